@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/connectors/kafka"
+	"github.com/yngvark/gr-zombie/pkg/connectors/pulsar"
+	gamelogicPkg "github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+)
+
+const (
+	defaultMapWidth  = 20
+	defaultMapHeight = 10
+)
+
+func newBroadcasterConfig(getEnv getEnv) (broadcast.Config, error) {
+	config := broadcast.DefaultConfig()
+
+	err := envInt(getEnv, "GAME_BROADCAST_QUEUE_SIZE", &config.QueueSize)
+	if err != nil {
+		return broadcast.Config{}, err
+	}
+
+	if policyName := getEnv("GAME_BROADCAST_OVERFLOW_POLICY"); policyName != "" {
+		policy, err := broadcast.ParseOverflowPolicy(policyName)
+		if err != nil {
+			return broadcast.Config{}, fmt.Errorf("parsing GAME_BROADCAST_OVERFLOW_POLICY: %w", err)
+		}
+
+		config.OverflowPolicy = policy
+	}
+
+	return config, nil
+}
+
+func newLobbyConfig(getEnv getEnv) (lobby.Config, error) {
+	config := lobby.DefaultConfig()
+
+	err := envInt(getEnv, "GAME_ROOM_SIZE", &config.Size)
+	if err != nil {
+		return lobby.Config{}, err
+	}
+
+	err = envDuration(getEnv, "GAME_LOBBY_COUNTDOWN", &config.Countdown)
+	if err != nil {
+		return lobby.Config{}, err
+	}
+
+	return config, nil
+}
+
+func newKafkaConfig(getEnv getEnv) (kafka.Config, error) {
+	config := kafka.DefaultConfig()
+
+	if brokers := getEnv("GAME_KAFKA_BROKERS"); brokers != "" {
+		config.Brokers = nil
+
+		for _, broker := range strings.Split(brokers, ",") {
+			config.Brokers = append(config.Brokers, strings.TrimSpace(broker))
+		}
+	}
+
+	envString(getEnv, "GAME_QUEUE_CLIENT_TOPIC", &config.ClientTopic)
+	envString(getEnv, "GAME_QUEUE_SERVER_TOPIC", &config.ServerTopic)
+	envString(getEnv, "GAME_KAFKA_GROUP_ID", &config.GroupID)
+
+	err := envDuration(getEnv, "GAME_QUEUE_CLIENT_IDLE_TIMEOUT", &config.ClientIdleTimeout)
+	if err != nil {
+		return kafka.Config{}, err
+	}
+
+	err = envDuration(getEnv, "GAME_KAFKA_DIAL_TIMEOUT", &config.DialTimeout)
+	if err != nil {
+		return kafka.Config{}, err
+	}
+
+	err = envDuration(getEnv, "GAME_KAFKA_WRITE_TIMEOUT", &config.WriteTimeout)
+	if err != nil {
+		return kafka.Config{}, err
+	}
+
+	err = envBool(getEnv, "GAME_KAFKA_TLS", &config.TLS.Enabled)
+	if err != nil {
+		return kafka.Config{}, err
+	}
+
+	envString(getEnv, "GAME_KAFKA_TLS_CA_FILE", &config.TLS.CAFile)
+	envString(getEnv, "GAME_KAFKA_TLS_CERT_FILE", &config.TLS.CertFile)
+	envString(getEnv, "GAME_KAFKA_TLS_KEY_FILE", &config.TLS.KeyFile)
+
+	err = envBool(getEnv, "GAME_KAFKA_TLS_INSECURE_SKIP_VERIFY", &config.TLS.InsecureSkipVerify)
+	if err != nil {
+		return kafka.Config{}, err
+	}
+
+	envString(getEnv, "GAME_KAFKA_SASL_MECHANISM", &config.SASL.Mechanism)
+	envString(getEnv, "GAME_KAFKA_SASL_USERNAME", &config.SASL.Username)
+	envString(getEnv, "GAME_KAFKA_SASL_PASSWORD", &config.SASL.Password)
+
+	return config, nil
+}
+
+func newPulsarConfig(getEnv getEnv) (pulsar.Config, error) {
+	config := pulsar.DefaultConfig()
+
+	envString(getEnv, "GAME_PULSAR_URL", &config.URL)
+	envString(getEnv, "GAME_QUEUE_CLIENT_TOPIC", &config.ClientTopic)
+	envString(getEnv, "GAME_QUEUE_SERVER_TOPIC", &config.ServerTopic)
+	envString(getEnv, "GAME_PULSAR_SUBSCRIPTION", &config.SubscriptionName)
+
+	if typeName := getEnv("GAME_PULSAR_SUBSCRIPTION_TYPE"); typeName != "" {
+		subscriptionType, err := pulsar.ParseSubscriptionType(typeName)
+		if err != nil {
+			return pulsar.Config{}, fmt.Errorf("parsing GAME_PULSAR_SUBSCRIPTION_TYPE: %w", err)
+		}
+
+		config.SubscriptionType = subscriptionType
+	}
+
+	err := envDuration(getEnv, "GAME_QUEUE_CLIENT_IDLE_TIMEOUT", &config.ClientIdleTimeout)
+	if err != nil {
+		return pulsar.Config{}, err
+	}
+
+	err = envDuration(getEnv, "GAME_PULSAR_CONNECTION_TIMEOUT", &config.ConnectionTimeout)
+	if err != nil {
+		return pulsar.Config{}, err
+	}
+
+	err = envDuration(getEnv, "GAME_PULSAR_OPERATION_TIMEOUT", &config.OperationTimeout)
+	if err != nil {
+		return pulsar.Config{}, err
+	}
+
+	envString(getEnv, "GAME_PULSAR_TLS_TRUST_CERTS_FILE", &config.TLS.TrustCertsFile)
+	envString(getEnv, "GAME_PULSAR_TLS_CERT_FILE", &config.TLS.CertFile)
+	envString(getEnv, "GAME_PULSAR_TLS_KEY_FILE", &config.TLS.KeyFile)
+
+	err = envBool(getEnv, "GAME_PULSAR_TLS_ALLOW_INSECURE_CONNECTION", &config.TLS.AllowInsecureConnection)
+	if err != nil {
+		return pulsar.Config{}, err
+	}
+
+	err = envBool(getEnv, "GAME_PULSAR_TLS_VALIDATE_HOSTNAME", &config.TLS.ValidateHostname)
+	if err != nil {
+		return pulsar.Config{}, err
+	}
+
+	envString(getEnv, "GAME_PULSAR_AUTH_TOKEN", &config.AuthToken)
+
+	return config, nil
+}
+
+func newGameConfig(getEnv getEnv) (gamelogicPkg.Config, error) {
+	config := gamelogicPkg.DefaultConfig()
+
+	err := envInt(getEnv, "GAME_ZOMBIE_COUNT", &config.ZombieCount)
+	if err != nil {
+		return gamelogicPkg.Config{}, err
+	}
+
+	if config.ZombieCount < 0 {
+		return gamelogicPkg.Config{}, fmt.Errorf("GAME_ZOMBIE_COUNT must not be negative, was %d", config.ZombieCount)
+	}
+
+	if placementName := getEnv("GAME_ZOMBIE_PLACEMENT"); placementName != "" {
+		placement, err := gamelogicPkg.ParsePlacement(placementName)
+		if err != nil {
+			return gamelogicPkg.Config{}, fmt.Errorf("parsing GAME_ZOMBIE_PLACEMENT: %w", err)
+		}
+
+		config.Placement = placement
+	}
+
+	if behaviourNames := getEnv("GAME_ZOMBIE_BEHAVIOURS"); behaviourNames != "" {
+		for _, name := range strings.Split(behaviourNames, ",") {
+			behaviour, err := gamelogicPkg.ParseBehaviourKind(strings.TrimSpace(name))
+			if err != nil {
+				return gamelogicPkg.Config{}, fmt.Errorf("parsing GAME_ZOMBIE_BEHAVIOURS: %w", err)
+			}
+
+			config.Behaviours = append(config.Behaviours, behaviour)
+		}
+	}
+
+	err = envInt64(getEnv, "GAME_SEED", &config.Seed)
+	if err != nil {
+		return gamelogicPkg.Config{}, err
+	}
+
+	err = envDuration(getEnv, "GAME_TICK_INTERVAL", &config.TickInterval)
+	if err != nil {
+		return gamelogicPkg.Config{}, err
+	}
+
+	err = envUint64(getEnv, "GAME_KEYFRAME_INTERVAL", &config.KeyframeInterval)
+	if err != nil {
+		return gamelogicPkg.Config{}, err
+	}
+
+	config.WorldMap, err = newWorldMap(getEnv, config.Seed)
+	if err != nil {
+		return gamelogicPkg.Config{}, err
+	}
+
+	return config, nil
+}
+
+// newWorldMap returns the world map configured by GAME_MAP_FILE or GAME_MAP_GENERATOR, or nil if neither is set
+func newWorldMap(getEnv getEnv, defaultSeed int64) (*worldmap.WorldMap, error) {
+	if mapFile := getEnv("GAME_MAP_FILE"); mapFile != "" {
+		m, err := worldmap.Load(mapFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading GAME_MAP_FILE: %w", err)
+		}
+
+		return m, nil
+	}
+
+	generator := getEnv("GAME_MAP_GENERATOR")
+	if generator == "" {
+		return nil, nil
+	}
+
+	width, height, seed := defaultMapWidth, defaultMapHeight, defaultSeed
+
+	err := envInt(getEnv, "GAME_MAP_WIDTH", &width)
+	if err != nil {
+		return nil, err
+	}
+
+	err = envInt(getEnv, "GAME_MAP_HEIGHT", &height)
+	if err != nil {
+		return nil, err
+	}
+
+	err = envInt64(getEnv, "GAME_MAP_SEED", &seed)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := worldmap.Generate(generator, width, height, seed)
+	if err != nil {
+		return nil, fmt.Errorf("generating map with GAME_MAP_GENERATOR: %w", err)
+	}
+
+	return m, nil
+}
+
+// envString sets value to the environment variable's value, if it is set
+func envString(getEnv getEnv, key string, value *string) {
+	if s := getEnv(key); s != "" {
+		*value = s
+	}
+}
+
+// envBool sets value to the environment variable's value, if it is set
+func envBool(getEnv getEnv, key string, value *bool) error {
+	if s := getEnv(key); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", key, err)
+		}
+
+		*value = b
+	}
+
+	return nil
+}
+
+// envInt sets value to the environment variable's value, if it is set
+func envInt(getEnv getEnv, key string, value *int) error {
+	if s := getEnv(key); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", key, err)
+		}
+
+		*value = i
+	}
+
+	return nil
+}
+
+// envInt64 sets value to the environment variable's value, if it is set
+func envInt64(getEnv getEnv, key string, value *int64) error {
+	if s := getEnv(key); s != "" {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", key, err)
+		}
+
+		*value = i
+	}
+
+	return nil
+}
+
+// envUint64 sets value to the environment variable's value, if it is set
+func envUint64(getEnv getEnv, key string, value *uint64) error {
+	if s := getEnv(key); s != "" {
+		i, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", key, err)
+		}
+
+		*value = i
+	}
+
+	return nil
+}
+
+// envDuration sets value to the environment variable's value, if it is set
+func envDuration(getEnv getEnv, key string, value *time.Duration) error {
+	if s := getEnv(key); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", key, err)
+		}
+
+		*value = d
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yngvark/gr-zombie/pkg/room"
+	"go.uber.org/zap"
+)
+
+const gameControlPath = "/game/"
+
+// newGameControlHandler returns a HTTP handler for controlling the speed of the games in the rooms. Endpoints:
+//
+// GET  /game/rooms            Lists the rooms
+// GET  /game/status           Returns the game's status
+// POST /game/pause            Pauses the game
+// POST /game/resume           Resumes the game
+// POST /game/step             Advances a paused game one tick
+// POST /game/speed?value=0.5  Sets the game speed, relative to the configured tick interval, up to gamelogic.MaxSpeed
+//
+// A game's ticks are only run after it has started, see lobby.Lobby, so pausing a game that hasn't started makes it
+// start paused.
+//
+// The endpoints controlling a game take the room as the query parameter "room", for instance /game/pause?room=abc.
+func newGameControlHandler(logger *zap.SugaredLogger, rooms *room.Manager) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		action := strings.TrimPrefix(request.URL.Path, gameControlPath)
+
+		readOnly := action == "status" || action == "rooms"
+		if !readOnly && request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if action == "rooms" {
+			writeJSON(logger, writer, rooms.Rooms())
+			return
+		}
+
+		r, ok := rooms.Room(request.URL.Query().Get("room"))
+		if !ok {
+			http.Error(writer, "no such room", http.StatusNotFound)
+			return
+		}
+
+		gameLogic := r.Logic()
+
+		switch action {
+		case "status":
+		case "pause":
+			gameLogic.Pause()
+		case "resume":
+			gameLogic.Resume()
+		case "step":
+			gameLogic.Step()
+		case "speed":
+			speed, err := strconv.ParseFloat(request.URL.Query().Get("value"), 64)
+			if err != nil {
+				http.Error(writer, "invalid speed: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			err = gameLogic.SetSpeed(speed)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			http.NotFound(writer, request)
+			return
+		}
+
+		if !readOnly {
+			logger.Infof("Game control in room %s: %s", r.ID, action)
+		}
+
+		writeJSON(logger, writer, gameLogic.Status())
+	}
+}
+
+func writeJSON(logger *zap.SugaredLogger, writer http.ResponseWriter, v interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(writer).Encode(v)
+	if err != nil {
+		logger.Errorf("writing JSON response: %s", err.Error())
+	}
+}
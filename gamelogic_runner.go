@@ -1,11 +1,12 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/yngvark/gr-zombie/pkg/connectors"
-	gamelogicPkg "github.com/yngvark/gr-zombie/pkg/gamelogic"
-	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/room"
+	"net/http"
 )
 
 func runGameLogic(o *GameOpts) error {
@@ -17,38 +18,66 @@ func runGameLogic(o *GameOpts) error {
 		}
 	}()
 
-	// Create game
-	gameLogic := gamelogicPkg.NewGameLogic(o.context, o.log, o.broadcaster)
+	// Games are created in rooms as clients join them, and start when the players in the room are ready
+	rooms := room.NewManager(o.context, o.log, o.lobbyConfig, o.broadcasterConfig, o.newGameConfig)
 
-	err := o.connector.ListenForConnections(createOnConnect(o))
+	http.Handle(gameControlPath, newGameControlHandler(o.log, rooms))
+	http.Handle(lobbyPath, newLobbyHandler(o.log, rooms))
+
+	go handleClientMessages(o, rooms)
+
+	err := o.connector.ListenForConnections(createOnConnect(o, rooms), createOnDisconnect(o, rooms))
 	if err != nil {
 		o.log.Errorf("Error listening for connections: %s", err.Error())
 		o.cancelFn()
 	}
 
-	o.log.Info("Running game")
-	gameLogic.Run()
-	o.log.Info("Done running game")
-
-	o.log.Debug("runGameLogic: cancelFn")
-	o.cancelFn()
+	o.log.Info("Running games")
+	<-o.context.Done()
+	o.log.Info("Done running games")
 
 	return nil
 }
 
-func createOnConnect(o *GameOpts) connectors.OnConnect {
-	return func(messagesToClientChannel chan string) error {
-		o.log.Debug("Client connected. Sending world map.")
-
-		wmap := worldmap.New(30, 30)
+func createOnConnect(o *GameOpts, rooms *room.Manager) connectors.OnConnect {
+	return func(client connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+		if client.Room == "" {
+			o.log.Debugf("Client %s connected. Finding a room.", client.ID)
+		} else {
+			o.log.Debugf("Client %s connected. Joining room %q.", client.ID, client.Room)
+		}
 
-		wmapJSON, err := json.Marshal(wmap)
+		messages, err := rooms.Join(client.Room, client.ID, subscribe)
 		if err != nil {
-			return fmt.Errorf("could not marshal world map: %w", err)
+			return nil, fmt.Errorf("could not join room: %w", err)
 		}
 
-		messagesToClientChannel <- string(wmapJSON)
+		return messages, nil
+	}
+}
+
+func createOnDisconnect(o *GameOpts, rooms *room.Manager) connectors.OnDisconnect {
+	return func(clientID string) {
+		o.log.Debugf("Client %s disconnected. Leaving room.", clientID)
 
-		return nil
+		err := rooms.Leave(clientID)
+		if err != nil {
+			o.log.Errorf("could not leave room: %s", err.Error())
+		}
+	}
+}
+
+// handleClientMessages dispatches messages from clients to the handlers of their rooms, until the context is canceled
+func handleClientMessages(o *GameOpts, rooms *room.Manager) {
+	for {
+		select {
+		case <-o.context.Done():
+			return
+		case msg := <-o.subscriber:
+			err := rooms.Dispatch(msg.ClientID, msg.Message)
+			if err != nil {
+				o.log.Infof("Ignoring invalid message from client %s: %s", msg.ClientID, err.Error())
+			}
+		}
 	}
 }
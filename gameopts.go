@@ -4,63 +4,107 @@ import (
 	"context"
 	"fmt"
 	"github.com/yngvark/gr-zombie/pkg/connectors"
+	gamelogicPkg "github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
 	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/room"
 	"os"
 
 	"github.com/yngvark/gr-zombie/pkg/connectors/websocket/oslookup"
 
+	"github.com/yngvark/gr-zombie/pkg/connectors/kafka"
 	"github.com/yngvark/gr-zombie/pkg/connectors/pulsar"
 	"github.com/yngvark/gr-zombie/pkg/connectors/websocket"
 	"github.com/yngvark/gr-zombie/pkg/log2"
-	"github.com/yngvark/gr-zombie/pkg/pubsub"
 	"go.uber.org/zap"
 )
 
 // GameOpts contains various dependencies
 type GameOpts struct {
-	context     context.Context
-	cancelFn    context.CancelFunc
-	log         *zap.SugaredLogger
-	subscriber  chan string
-	broadcaster *broadcast.Broadcaster
-	connector   connectors.Connector
+	context           context.Context
+	cancelFn          context.CancelFunc
+	log               *zap.SugaredLogger
+	subscriber        chan connectors.ClientMessage
+	registry          *protocol.Registry
+	lobbyConfig       lobby.Config
+	broadcasterConfig broadcast.Config
+	connector         connectors.Connector
+	// newGameConfig returns the config for the game in a new room
+	newGameConfig room.NewConfig
 }
 
 type getEnv func(key string) string
 
-//goland:noinspection GoUnusedParameter
 func newGameOpts(ctx context.Context, cancelFn context.CancelFunc, getEnv getEnv) (*GameOpts, error) {
 	log, err := log2.New()
 	if err != nil {
 		return nil, fmt.Errorf("could not create logger: %w", err)
 	}
 
-	broadcaster := broadcast.New(log)
+	lobbyConfig, err := newLobbyConfig(getEnv)
+	if err != nil {
+		return nil, fmt.Errorf("creating lobby config: %w", err)
+	}
+
+	broadcasterConfig, err := newBroadcasterConfig(getEnv)
+	if err != nil {
+		return nil, fmt.Errorf("creating broadcaster config: %w", err)
+	}
+
+	// Fail fast on invalid config, rather than when the first room is created
+	_, err = newGameConfig(getEnv)
+	if err != nil {
+		return nil, fmt.Errorf("creating game config: %w", err)
+	}
 
 	var connector connectors.Connector
 
-	subscriber := make(chan string)
+	subscriber := make(chan connectors.ClientMessage)
+	registry := gamelogicPkg.NewRegistry()
 
-	switch {
-	//case getEnv("GAME_QUEUE_TYPE") == "kafka":
-	//	consumer, err = pubSubForKafka(ctx, cancelFn, logger, subscriber)
-	//	if err != nil {
-	//		return nil, fmt.Errorf("creating pulsar connectors: %w", err)
-	//	}
-	default:
-		connector, err = newWebsocketConnector(ctx, log, subscriber, broadcaster)
+	switch queueType := getEnv("GAME_QUEUE_TYPE"); queueType {
+	case "kafka":
+		config, configErr := newKafkaConfig(getEnv)
+		if configErr != nil {
+			return nil, fmt.Errorf("creating kafka config: %w", configErr)
+		}
+
+		connector, err = kafka.NewConnector(ctx, log, config, subscriber, registry)
+		if err != nil {
+			return nil, fmt.Errorf("creating kafka connector: %w", err)
+		}
+	case "pulsar":
+		config, configErr := newPulsarConfig(getEnv)
+		if configErr != nil {
+			return nil, fmt.Errorf("creating pulsar config: %w", configErr)
+		}
+
+		connector, err = pulsar.NewConnector(ctx, log, config, subscriber, registry)
+		if err != nil {
+			return nil, fmt.Errorf("creating pulsar connector: %w", err)
+		}
+	case "", "websocket":
+		connector, err = newWebsocketConnector(ctx, log, subscriber, registry)
 		if err != nil {
 			return nil, fmt.Errorf("creating websocket connectors: %w", err)
 		}
+	default:
+		return nil, fmt.Errorf("unknown GAME_QUEUE_TYPE %q, must be websocket, kafka or pulsar", queueType)
 	}
 
 	return &GameOpts{
-		context:     ctx,
-		cancelFn:    cancelFn,
-		log:         log,
-		subscriber:  subscriber,
-		broadcaster: broadcaster,
-		connector:   connector,
+		context:           ctx,
+		cancelFn:          cancelFn,
+		log:               log,
+		subscriber:        subscriber,
+		registry:          registry,
+		lobbyConfig:       lobbyConfig,
+		broadcasterConfig: broadcasterConfig,
+		connector:         connector,
+		newGameConfig: func(string) (gamelogicPkg.Config, error) {
+			return newGameConfig(getEnv)
+		},
 	}, nil
 }
 
@@ -69,8 +113,8 @@ const allowedCorsOriginsEnvVarKey = "ALLOWED_CORS_ORIGINS"
 func newWebsocketConnector(
 	ctx context.Context,
 	logger *zap.SugaredLogger,
-	subscriber chan string,
-	broadcaster *broadcast.Broadcaster,
+	subscriber chan connectors.ClientMessage,
+	registry *protocol.Registry,
 ) (connectors.Connector, error) {
 	corsHelper := oslookup.NewCORSHelper(logger)
 
@@ -81,44 +125,7 @@ func newWebsocketConnector(
 
 	corsHelper.PrintAllowedCorsOrigins(allowedCorsOrigins)
 
-	c := websocket.NewConnector(ctx, logger, subscriber, allowedCorsOrigins, broadcaster)
+	c := websocket.NewConnector(ctx, logger, subscriber, allowedCorsOrigins, registry)
 
 	return c, nil
 }
-
-//goland:noinspection GoUnusedFunction
-func pubSubForPulsar(
-	ctx context.Context,
-	cancelFn context.CancelFunc,
-	logger *zap.SugaredLogger,
-	subscriber chan string,
-) (pubsub.Publisher, pubsub.Consumer, error) {
-	p, err := pulsar.NewPublisher(ctx, cancelFn, logger, "zombie")
-	if err != nil {
-		return nil, nil, fmt.Errorf("creating publisher: %w", err)
-	}
-
-	c, err := pulsar.NewConsumer(ctx, logger, "gameinit", subscriber)
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not create consumer: %w", err)
-	}
-
-	return p, c, nil
-}
-
-/*func pubSubForKafka(
-	ctx context.Context,
-	cancelFn context.CancelFunc,
-	logger *zap.SugaredLogger,
-	subscriber chan string,
-) (pubsub.Publisher, pubsub.Consumer, error) {
-	p, err := kafka.NewPublisher(ctx, cancelFn, logger, "zombie")
-	if err != nil {
-		return nil, nil, fmt.Errorf("creating publisher: %w", err)
-	}
-
-	c, err := kafka.NewConsumer(ctx, logger, "gameinit", subscriber)
-
-	return p, c, nil
-}
-*/
@@ -8,8 +8,10 @@ require (
 	github.com/gorilla/websocket v1.4.2
 	github.com/segmentio/kafka-go v0.4.25
 	github.com/stretchr/testify v1.6.1
+	github.com/vmihailenco/msgpack/v5 v5.3.5
 	go.uber.org/zap v1.16.0
 	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9 // indirect
 	golang.org/x/mod v0.5.0 // indirect
 	golang.org/x/tools v0.1.6 // indirect
+	google.golang.org/protobuf v1.23.0
 )
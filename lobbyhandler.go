@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/yngvark/gr-zombie/pkg/room"
+	"go.uber.org/zap"
+)
+
+const lobbyPath = "/lobby/games"
+
+// newLobbyHandler returns a HTTP handler listing the games players can join, with their player counts and settings.
+// Clients join a listed game by connecting with its ID as the "room" query parameter, or let the server pick a game
+// for them by leaving the parameter out.
+func newLobbyHandler(logger *zap.SugaredLogger, rooms *room.Manager) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(logger, writer, rooms.OpenRooms())
+	}
+}
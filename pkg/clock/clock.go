@@ -0,0 +1,54 @@
+// Package clock knows how to tell time. Depending on a Clock instead of the time package makes it possible to control
+// time in tests.
+package clock
+
+import "time"
+
+// Clock tells time
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTicker returns a new Ticker that ticks every interval
+	NewTicker(interval time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the Ticker
+	Stop()
+	// Reset stops the Ticker and resets its interval
+	Reset(interval time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(interval time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(interval)}
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+func (t realTicker) Reset(interval time.Duration) {
+	t.ticker.Reset(interval)
+}
+
+// New returns a Clock that uses the real wall clock
+func New() Clock {
+	return realClock{}
+}
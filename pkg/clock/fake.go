@@ -0,0 +1,158 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to, by calling Advance. It is meant for tests.
+type Fake struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// Now returns the fake current time
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.now
+}
+
+// NewTicker returns a new Ticker that ticks every interval, measured by the fake clock
+func (f *Fake) NewTicker(interval time.Duration) Ticker {
+	if interval <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	t := &fakeTicker{
+		clock:    f,
+		c:        make(chan time.Time),
+		stopped:  make(chan struct{}),
+		interval: interval,
+		next:     f.now.Add(interval),
+	}
+
+	f.tickers = append(f.tickers, t)
+	f.cond.Broadcast()
+
+	return t
+}
+
+// Advance moves the clock forward. Ticks that become due are delivered in order, and Advance waits for each tick to be
+// received before continuing. Hence, when Advance returns, every due tick has been received by its reader.
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	end := f.now.Add(d)
+	f.mutex.Unlock()
+
+	for {
+		t, tickTime, ok := f.nextDueTick(end)
+		if !ok {
+			break
+		}
+
+		select {
+		case t.c <- tickTime:
+		case <-t.stopped:
+		}
+	}
+
+	f.mutex.Lock()
+	f.now = end
+	f.mutex.Unlock()
+}
+
+// nextDueTick finds the earliest tick due at or before end, moves the clock to it and schedules the ticker's next tick
+func (f *Fake) nextDueTick(end time.Time) (*fakeTicker, time.Time, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sort.SliceStable(f.tickers, func(i, j int) bool {
+		return f.tickers[i].next.Before(f.tickers[j].next)
+	})
+
+	if len(f.tickers) == 0 || f.tickers[0].next.After(end) {
+		return nil, time.Time{}, false
+	}
+
+	t := f.tickers[0]
+	tickTime := t.next
+
+	f.now = tickTime
+	t.next = tickTime.Add(t.interval)
+
+	return t, tickTime, true
+}
+
+// WaitForTickers blocks until at least count tickers are running. Use it to make sure the code under test has started
+// its ticker before calling Advance.
+func (f *Fake) WaitForTickers(count int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for len(f.tickers) < count {
+		f.cond.Wait()
+	}
+}
+
+func (f *Fake) removeTicker(t *fakeTicker) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i, ticker := range f.tickers {
+		if ticker == t {
+			f.tickers = append(f.tickers[:i], f.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock    *Fake
+	c        chan time.Time
+	stopped  chan struct{}
+	stopOnce sync.Once
+	interval time.Duration
+	next     time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.removeTicker(t)
+	t.stopOnce.Do(func() {
+		close(t.stopped)
+	})
+}
+
+func (t *fakeTicker) Reset(interval time.Duration) {
+	if interval <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	t.interval = interval
+	t.next = t.clock.now.Add(interval)
+}
+
+// NewFake returns a new Fake clock, starting at the given time
+func NewFake(now time.Time) *Fake {
+	f := &Fake{
+		now: now,
+	}
+
+	f.cond = sync.NewCond(&f.mutex)
+
+	return f
+}
@@ -0,0 +1,87 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Unix(0, 0)
+
+	t.Run("Should deliver each due tick when advancing", func(t *testing.T) {
+		// Given
+		fakeClock := clock.NewFake(start)
+		ticker := fakeClock.NewTicker(time.Second)
+		ticks := receiveTicks(ticker)
+
+		// When
+		fakeClock.Advance(3500 * time.Millisecond)
+
+		// Then
+		assert.Equal(t, []time.Time{
+			start.Add(1 * time.Second),
+			start.Add(2 * time.Second),
+			start.Add(3 * time.Second),
+		}, ticks())
+		assert.Equal(t, start.Add(3500*time.Millisecond), fakeClock.Now())
+	})
+
+	t.Run("Should use the new interval after reset", func(t *testing.T) {
+		// Given
+		fakeClock := clock.NewFake(start)
+		ticker := fakeClock.NewTicker(time.Second)
+		ticks := receiveTicks(ticker)
+
+		// When
+		ticker.Reset(2 * time.Second)
+		fakeClock.Advance(4 * time.Second)
+
+		// Then
+		assert.Equal(t, []time.Time{
+			start.Add(2 * time.Second),
+			start.Add(4 * time.Second),
+		}, ticks())
+	})
+
+	t.Run("Should not tick after stop", func(t *testing.T) {
+		// Given
+		fakeClock := clock.NewFake(start)
+		ticker := fakeClock.NewTicker(time.Second)
+
+		// When
+		ticker.Stop()
+
+		// Then
+		assert.NotPanics(t, func() {
+			fakeClock.Advance(time.Minute)
+		})
+	})
+}
+
+// receiveTicks receives ticks in the background. The returned function returns the ticks received so far.
+func receiveTicks(ticker clock.Ticker) func() []time.Time {
+	received := make(chan time.Time, 100)
+
+	go func() {
+		for tick := range ticker.C() {
+			received <- tick
+		}
+	}()
+
+	return func() []time.Time {
+		ticks := make([]time.Time, 0)
+
+		for {
+			select {
+			case tick := <-received:
+				ticks = append(ticks, tick)
+			case <-time.After(50 * time.Millisecond):
+				return ticks
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package bus
+
+import (
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+)
+
+// DefaultIdleTimeout is the default Config.IdleTimeout
+const DefaultIdleTimeout = 30 * time.Second
+
+// Config contains settings for the Connector
+type Config struct {
+	// IdleTimeout is how long a client can go without sending a frame before it is disconnected. Clients with nothing
+	// else to send must send FramePing more often than this. If 0, clients are never disconnected for being idle. Clients
+	// are checked for being idle at most once per millisecond, however short the timeout is.
+	IdleTimeout time.Duration
+	// Clock is used for timing out idle clients. If nil, the real wall clock is used.
+	Clock clock.Clock
+}
+
+// DefaultConfig returns the default Config
+func DefaultConfig() Config {
+	return Config{
+		IdleTimeout: DefaultIdleTimeout,
+	}
+}
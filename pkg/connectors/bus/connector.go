@@ -0,0 +1,406 @@
+// Package bus connects clients to the game through a message bus, like Kafka or Pulsar. Clients send Frames to one
+// topic, by default DefaultClientTopic, and receive Frames on another, by default DefaultServerTopic. Protocol messages
+// in the frames are always encoded with protocol.JSON.
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/connectors"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+	"go.uber.org/zap"
+)
+
+// idleChecksPerTimeout is how many times per idle timeout clients are checked for being idle. Clients are hence
+// disconnected at most idleTimeout/idleChecksPerTimeout after timing out.
+const idleChecksPerTimeout = 2
+
+// minIdleCheckInterval is the shortest interval between checks for idle clients, for very short idle timeouts
+const minIdleCheckInterval = time.Millisecond
+
+type connector struct {
+	ctx        context.Context
+	log        *zap.SugaredLogger
+	publisher  pubsub.Publisher
+	consumer   pubsub.Consumer
+	subscriber chan connectors.ClientMessage
+	registry   *protocol.Registry
+	// idleTimeout is how long clients can go without sending a frame, see Config.IdleTimeout
+	idleTimeout time.Duration
+	clock       clock.Clock
+
+	listening    bool
+	onConnect    connectors.OnConnect
+	onDisconnect connectors.OnDisconnect
+
+	// publishMutex is held while publishing, as publishers aren't necessarily safe for concurrent use
+	publishMutex sync.Mutex
+	// mutex protects clients
+	mutex sync.Mutex
+	// clients are the connected clients, by ID
+	clients map[string]*client
+}
+
+// client is a client connected through the message bus
+type client struct {
+	id       string
+	ctx      context.Context
+	cancelFn context.CancelFunc
+	session  *connectors.Session
+	// lastSeen is when the client last sent a frame. It's protected by the connector's mutex.
+	lastSeen time.Time
+	// connecting is true until onConnect has returned. Connecting clients don't time out, so they aren't removed while
+	// being subscribed or added to a room. It's protected by the connector's mutex.
+	connecting bool
+}
+
+// ListenForConnections starts consuming frames from clients. It doesn't block.
+func (c *connector) ListenForConnections(onConnect connectors.OnConnect, onDisconnect connectors.OnDisconnect) error {
+	if c.listening {
+		return errors.New("already listening for connections. Can listen for connections only once")
+	}
+
+	c.listening = true
+	c.onConnect = onConnect
+	c.onDisconnect = onDisconnect
+
+	go func() {
+		err := c.consumer.ListenForMessages()
+		if err != nil {
+			c.log.Errorf("Consuming frames from clients: %s", err.Error())
+		}
+	}()
+
+	go c.readFrames()
+
+	if c.idleTimeout > 0 {
+		go c.removeIdleClients()
+	}
+
+	return nil
+}
+
+// StopListening closes the publisher and consumer
+func (c *connector) StopListening() error {
+	c.log.Info("bus.connector.StopListening")
+
+	consumerErr := c.consumer.Close()
+	publisherErr := c.publisher.Close()
+
+	if consumerErr != nil {
+		return fmt.Errorf("closing consumer: %w", consumerErr)
+	}
+
+	if publisherErr != nil {
+		return fmt.Errorf("closing publisher: %w", publisherErr)
+	}
+
+	return nil
+}
+
+// readFrames handles frames from clients until the context is canceled
+func (c *connector) readFrames() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case msg := <-c.consumer.SubscriberChannel():
+			c.handleFrame(msg)
+		}
+	}
+}
+
+func (c *connector) handleFrame(msg string) {
+	var frame Frame
+
+	err := json.Unmarshal([]byte(msg), &frame)
+	if err != nil {
+		c.log.Infof("Ignoring invalid frame: %s", err.Error())
+		return
+	}
+
+	if frame.Client == "" {
+		c.log.Infof("Ignoring %s frame without client ID", frame.Kind)
+		return
+	}
+
+	c.seen(frame.Client)
+
+	switch frame.Kind {
+	case FrameConnect:
+		c.connect(frame)
+	case FrameDisconnect:
+		if cl, ok := c.client(frame.Client); ok {
+			c.log.Infof("Client %s disconnected", cl.id)
+			c.remove(cl, "")
+		}
+	case FrameMessage:
+		c.receive(frame)
+	case FramePing:
+		c.pong(frame.Client)
+	default:
+		c.log.Infof("Ignoring frame of unknown kind %q from client %s", frame.Kind, frame.Client)
+	}
+}
+
+// connect connects a client, and starts forwarding messages to it
+func (c *connector) connect(frame Frame) {
+	if !protocol.Supports(frame.Version) {
+		c.log.Infof("Rejecting client %s speaking unsupported protocol version %d", frame.Client, frame.Version)
+		c.publish(Frame{
+			Client: frame.Client,
+			Kind:   FrameClosed,
+			Error:  fmt.Sprintf("unsupported protocol version, server supports %v", protocol.SupportedVersions),
+		})
+
+		return
+	}
+
+	ctx, cancelFn := context.WithCancel(c.ctx)
+	cl := &client{
+		id:         frame.Client,
+		ctx:        ctx,
+		cancelFn:   cancelFn,
+		session:    connectors.NewSession(frame.Client, frame.Topics, c.registry, protocol.JSON),
+		lastSeen:   c.clock.Now(),
+		connecting: true,
+	}
+
+	c.mutex.Lock()
+
+	if _, ok := c.clients[cl.id]; ok {
+		c.mutex.Unlock()
+		cancelFn()
+		c.log.Infof("Ignoring connect from client %s, which is already connected", cl.id)
+
+		return
+	}
+
+	c.clients[cl.id] = cl
+	c.mutex.Unlock()
+
+	c.log.Infof("Client %s connected", cl.id)
+
+	messages, err := c.onConnect(connectors.Client{ID: cl.id, Room: frame.Room}, cl.session.Subscribe)
+	if err == nil && cl.session.Subscription() == nil {
+		err = errors.New("client wasn't subscribed to any broadcaster")
+	}
+
+	if err != nil {
+		c.log.Error("on connect:", err)
+		c.remove(cl, err.Error())
+
+		return
+	}
+
+	c.mutex.Lock()
+	cl.connecting = false
+	cl.lastSeen = c.clock.Now()
+	c.mutex.Unlock()
+
+	go c.forward(cl, messages)
+}
+
+// remove disconnects a client. If reason isn't empty, the client is told why.
+func (c *connector) remove(cl *client, reason string) {
+	c.mutex.Lock()
+
+	if c.clients[cl.id] != cl {
+		// Already removed
+		c.mutex.Unlock()
+		return
+	}
+
+	delete(c.clients, cl.id)
+	c.mutex.Unlock()
+
+	cl.cancelFn()
+	cl.session.Unsubscribe()
+	c.onDisconnect(cl.id)
+
+	if reason != "" {
+		c.publish(Frame{Client: cl.id, Kind: FrameClosed, Error: reason})
+	}
+}
+
+// seen notes that a client sent a frame just now, if it's connected
+func (c *connector) seen(id string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cl, ok := c.clients[id]; ok {
+		cl.lastSeen = c.clock.Now()
+	}
+}
+
+// pong answers a ping from a client. Clients that aren't connected, for instance because they timed out without hearing
+// about it, are told so.
+func (c *connector) pong(id string) {
+	if _, ok := c.client(id); !ok {
+		c.publish(Frame{Client: id, Kind: FrameClosed, Error: "not connected"})
+		return
+	}
+
+	c.publish(Frame{Client: id, Kind: FramePong})
+}
+
+// removeIdleClients disconnects clients that haven't sent a frame for idleTimeout, until the context is canceled
+func (c *connector) removeIdleClients() {
+	interval := c.idleTimeout / idleChecksPerTimeout
+	if interval < minIdleCheckInterval {
+		interval = minIdleCheckInterval
+	}
+
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-ticker.C():
+			for _, cl := range c.idleClients(now) {
+				c.log.Infof("Client %s hasn't sent anything for %s. Disconnecting it.", cl.id, c.idleTimeout)
+				c.remove(cl, "timed out")
+			}
+		}
+	}
+}
+
+// idleClients returns the clients that haven't sent a frame for idleTimeout at the given time. Connecting clients are
+// left out.
+func (c *connector) idleClients(now time.Time) []*client {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var idle []*client
+
+	for _, cl := range c.clients {
+		if !cl.connecting && !now.Before(cl.lastSeen.Add(c.idleTimeout)) {
+			idle = append(idle, cl)
+		}
+	}
+
+	return idle
+}
+
+func (c *connector) client(id string) (*client, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cl, ok := c.clients[id]
+
+	return cl, ok
+}
+
+// receive passes a message from a client on to the subscriber, unless the client's session handles it
+func (c *connector) receive(frame Frame) {
+	cl, ok := c.client(frame.Client)
+	if !ok {
+		c.log.Infof("Ignoring message from client %s, which isn't connected", frame.Client)
+		return
+	}
+
+	envelope, forGame, err := cl.session.Decode(frame.Message)
+	if err != nil {
+		c.log.Infof("Ignoring message from client %s: %s", cl.id, err.Error())
+		return
+	}
+
+	if !forGame {
+		return
+	}
+
+	select {
+	case c.subscriber <- connectors.ClientMessage{ClientID: cl.id, Message: envelope}:
+	case <-c.ctx.Done():
+	}
+}
+
+// forward sends the messages from onConnect, and then broadcasted messages, to a client until it's removed
+func (c *connector) forward(cl *client, messagesFromOnConnect []*protocol.Envelope) {
+	for _, msg := range messagesFromOnConnect {
+		c.send(cl, msg)
+	}
+
+	subscriber := cl.session.Subscription()
+
+	for {
+		select {
+		case msg := <-subscriber.Messages():
+			c.send(cl, msg)
+		case <-subscriber.Done():
+			c.log.Infof("Client %s was too slow to receive messages (%d dropped). Disconnecting it.", cl.id, subscriber.Dropped())
+			c.remove(cl, "too slow to receive messages")
+
+			return
+		case <-cl.ctx.Done():
+			return
+		}
+	}
+}
+
+// send sends a message to a client
+func (c *connector) send(cl *client, msg *protocol.Envelope) {
+	data, err := cl.session.Encode(msg)
+	if err != nil {
+		c.log.Errorf("Could not encode message, skipping it: %s", err.Error())
+		return
+	}
+
+	c.publish(Frame{Client: cl.id, Kind: FrameMessage, Message: data})
+}
+
+// publish sends a frame to a client. Frames that can't be sent are logged and skipped, so that a failing broker doesn't
+// stop the game. The client notices the gap in sequence numbers.
+func (c *connector) publish(frame Frame) {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		c.log.Errorf("Could not encode frame to client %s: %s", frame.Client, err.Error())
+		return
+	}
+
+	c.publishMutex.Lock()
+	defer c.publishMutex.Unlock()
+
+	err = c.publisher.SendMsg(string(data))
+	if err != nil {
+		c.log.Errorf("Could not send frame to client %s: %s", frame.Client, err.Error())
+	}
+}
+
+// NewConnector returns a Connector that talks with clients through a message bus. The consumer must consume the topic
+// clients send frames to, and the publisher must publish to the topic clients receive frames on.
+func NewConnector(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	config Config,
+	publisher pubsub.Publisher,
+	consumer pubsub.Consumer,
+	subscriber chan connectors.ClientMessage,
+	registry *protocol.Registry,
+) connectors.Connector {
+	connectorClock := config.Clock
+	if connectorClock == nil {
+		connectorClock = clock.New()
+	}
+
+	return &connector{
+		ctx:         ctx,
+		log:         logger,
+		publisher:   publisher,
+		consumer:    consumer,
+		subscriber:  subscriber,
+		registry:    registry,
+		idleTimeout: config.IdleTimeout,
+		clock:       connectorClock,
+		clients:     make(map[string]*client),
+	}
+}
@@ -0,0 +1,486 @@
+package bus_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/connectors"
+	"github.com/yngvark/gr-zombie/pkg/connectors/bus"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/memory"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestConnector(t *testing.T) {
+	t.Run("Should send the messages from onConnect first, and then what was broadcast after subscribing", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		onConnect := func(client connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+			subscribe(broadcaster)
+			return []*protocol.Envelope{message("welcome to " + client.Room)}, nil
+		}
+
+		broker := newConnector(ctx, t, onConnect, func(string) {}, nil)
+		client := newTestClient(ctx, broker, "a")
+
+		// When
+		client.send(t, bus.Frame{Kind: bus.FrameConnect, Version: protocol.Version, Room: "abc"})
+
+		// Then
+		welcome := client.readEnvelope(t)
+		assert.Equal(t, "welcome to abc", *welcome.Payload.(*string))
+		assert.Equal(t, uint64(1), welcome.Seq)
+
+		require.NoError(t, broadcaster.BroadCast(message("tick")))
+
+		tick := client.readEnvelope(t)
+		assert.Equal(t, "tick", *tick.Payload.(*string))
+		assert.Equal(t, uint64(2), tick.Seq)
+	})
+
+	t.Run("Should send only messages for the topics the client asked for", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		broker := newConnector(ctx, t, subscribeTo(broadcaster), func(string) {}, nil)
+		client := newTestClient(ctx, broker, "a")
+
+		client.connect(t, broadcast.TopicChat)
+
+		// When
+		require.NoError(t, broadcaster.Publish(broadcast.TopicZombies, message("zombie moved")))
+		require.NoError(t, broadcaster.Publish(broadcast.TopicChat, message("hi")))
+
+		// Then
+		assert.Equal(t, "hi", *client.readEnvelope(t).Payload.(*string))
+	})
+
+	t.Run("Should close the connection to clients that can't connect", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		disconnected := make(chan string, 1)
+		onConnect := func(connectors.Client, func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+			return nil, errors.New("room abc is full")
+		}
+
+		broker := newConnector(ctx, t, onConnect, func(clientID string) { disconnected <- clientID }, nil)
+		oldClient := newTestClient(ctx, broker, "old")
+		rejectedClient := newTestClient(ctx, broker, "rejected")
+
+		// When
+		oldClient.send(t, bus.Frame{Kind: bus.FrameConnect, Version: protocol.Version + 1})
+		rejectedClient.send(t, bus.Frame{Kind: bus.FrameConnect, Version: protocol.Version})
+
+		// Then
+		closed := oldClient.read(t)
+		assert.Equal(t, bus.FrameClosed, closed.Kind)
+		assert.Contains(t, closed.Error, "unsupported protocol version")
+
+		closed = rejectedClient.read(t)
+		assert.Equal(t, bus.FrameClosed, closed.Kind)
+		assert.Equal(t, "room abc is full", closed.Error)
+		assert.Equal(t, "rejected", <-disconnected)
+	})
+
+	t.Run("Should pass messages from clients on, tagged with client IDs, and tell when clients disconnect", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		disconnected := make(chan string, 1)
+		subscriber := make(chan connectors.ClientMessage, 1)
+
+		broker := newConnector(ctx, t, subscribeTo(broadcaster), func(clientID string) { disconnected <- clientID }, subscriber)
+		client := newTestClient(ctx, broker, "a")
+		client.connect(t)
+
+		// When
+		client.send(t, bus.Frame{Kind: bus.FrameMessage, Message: json.RawMessage(`"not a message"`)})
+		client.send(t, bus.Frame{Kind: bus.FrameMessage, Message: json.RawMessage(`{"v":1,"type":"test","payload":"hi"}`)})
+
+		// Then
+		msg := <-subscriber
+		assert.Equal(t, "a", msg.ClientID)
+		assert.Equal(t, "hi", *msg.Message.Payload.(*string))
+
+		// When
+		client.send(t, bus.Frame{Kind: bus.FrameDisconnect})
+
+		// Then
+		assert.Equal(t, "a", <-disconnected)
+	})
+
+	t.Run("Should keep running when sending a frame fails", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		subscriber := make(chan connectors.ClientMessage, 1)
+		setup := connectorSetup{
+			config: bus.DefaultConfig(),
+			wrapPublisher: func(publisher pubsub.Publisher) pubsub.Publisher {
+				return failingPublisher{Publisher: publisher, fail: "lost"}
+			},
+		}
+
+		broker := newConnectorWith(ctx, t, setup, subscribeTo(broadcaster), func(string) {}, subscriber)
+		client := newTestClient(ctx, broker, "a")
+		client.connect(t)
+
+		// When
+		require.NoError(t, broadcaster.BroadCast(message("lost")))
+		require.NoError(t, broadcaster.BroadCast(message("tick")))
+
+		// Then
+		tick := client.readEnvelope(t)
+		assert.Equal(t, "tick", *tick.Payload.(*string))
+		assert.Equal(t, uint64(3), tick.Seq)
+
+		client.send(t, bus.Frame{Kind: bus.FrameMessage, Message: json.RawMessage(`{"v":1,"type":"test","payload":"hi"}`)})
+		assert.Equal(t, "hi", *(<-subscriber).Message.Payload.(*string))
+	})
+
+	t.Run("Should disconnect clients that don't send anything for the idle timeout", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		fakeClock := clock.NewFake(time.Unix(0, 0))
+		setup := connectorSetup{config: bus.Config{IdleTimeout: 10 * time.Second, Clock: fakeClock}}
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		disconnected := make(chan string, 1)
+
+		broker := newConnectorWith(ctx, t, setup, subscribeTo(broadcaster), func(clientID string) { disconnected <- clientID }, nil)
+		client := newTestClient(ctx, broker, "a")
+		client.connect(t)
+
+		fakeClock.WaitForTickers(1)
+
+		// When
+		fakeClock.Advance(10 * time.Second)
+
+		// Then
+		assert.Equal(t, "a", <-disconnected)
+
+		closed := client.read(t)
+		assert.Equal(t, bus.FrameClosed, closed.Kind)
+		assert.Equal(t, "timed out", closed.Error)
+
+		client.send(t, bus.Frame{Kind: bus.FramePing})
+
+		closed = client.read(t)
+		assert.Equal(t, bus.FrameClosed, closed.Kind)
+		assert.Equal(t, "not connected", closed.Error)
+	})
+
+	t.Run("Should keep clients that ping", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		fakeClock := clock.NewFake(time.Unix(0, 0))
+		setup := connectorSetup{config: bus.Config{IdleTimeout: 10 * time.Second, Clock: fakeClock}}
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+
+		broker := newConnectorWith(ctx, t, setup, subscribeTo(broadcaster), func(string) {}, nil)
+		client := newTestClient(ctx, broker, "a")
+		client.connect(t)
+
+		fakeClock.WaitForTickers(1)
+
+		// When
+		for i := 0; i < 4; i++ {
+			client.send(t, bus.Frame{Kind: bus.FramePing})
+			require.Equal(t, bus.FramePong, client.read(t).Kind)
+
+			fakeClock.Advance(5 * time.Second)
+		}
+
+		// Then
+		require.NoError(t, broadcaster.BroadCast(message("tick")))
+		assert.Equal(t, "tick", *client.readEnvelope(t).Payload.(*string))
+	})
+
+	t.Run("Should not disconnect clients for being idle while they connect", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		fakeClock := clock.NewFake(time.Unix(0, 0))
+		setup := connectorSetup{config: bus.Config{IdleTimeout: 10 * time.Second, Clock: fakeClock}}
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		disconnected := make(chan string, 1)
+		connecting := make(chan struct{})
+		release := make(chan struct{})
+
+		onConnect := func(client connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+			close(connecting)
+			<-release
+
+			return subscribeTo(broadcaster)(client, subscribe)
+		}
+
+		broker := newConnectorWith(ctx, t, setup, onConnect, func(clientID string) { disconnected <- clientID }, nil)
+		client := newTestClient(ctx, broker, "a")
+
+		client.send(t, bus.Frame{Kind: bus.FrameConnect, Version: protocol.Version})
+		<-connecting
+
+		fakeClock.WaitForTickers(1)
+
+		// When
+		// The check at 10 seconds is done when the tick at 15 seconds is received
+		fakeClock.Advance(15 * time.Second)
+		close(release)
+
+		// Then
+		require.Equal(t, "welcome", *client.readEnvelope(t).Payload.(*string))
+		assert.Empty(t, disconnected)
+
+		require.NoError(t, broadcaster.BroadCast(message("tick")))
+		assert.Equal(t, "tick", *client.readEnvelope(t).Payload.(*string))
+	})
+
+	t.Run("Should disconnect idle clients when the idle timeout is too short to check twice", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		fakeClock := clock.NewFake(time.Unix(0, 0))
+		setup := connectorSetup{config: bus.Config{IdleTimeout: time.Nanosecond, Clock: fakeClock}}
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		disconnected := make(chan string, 1)
+
+		broker := newConnectorWith(ctx, t, setup, subscribeTo(broadcaster), func(clientID string) { disconnected <- clientID }, nil)
+		client := newTestClient(ctx, broker, "a")
+		client.connect(t)
+
+		fakeClock.WaitForTickers(1)
+
+		// When
+		fakeClock.Advance(time.Millisecond)
+
+		// Then
+		assert.Equal(t, "a", <-disconnected)
+	})
+
+	t.Run("Should send snapshots as deltas from the last snapshot the client acknowledged", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(zap.NewNop().Sugar())
+		subscriber := make(chan connectors.ClientMessage, 1)
+
+		broker := newConnector(ctx, t, subscribeTo(broadcaster), func(string) {}, subscriber)
+		client := newTestClient(ctx, broker, "a")
+		client.connect(t)
+
+		zombieAt := func(x int) []snapshot.Entity {
+			return []snapshot.Entity{{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: "1"}, X: x}}
+		}
+
+		publishSnapshot := func(tick uint64) *snapshot.Delta {
+			e := protocol.NewEnvelope(protocol.TypeWorldDelta, tick, snapshot.New(tick, false, zombieAt(int(tick))))
+			require.NoError(t, broadcaster.BroadCast(e))
+
+			return client.readEnvelope(t).Payload.(*snapshot.Delta)
+		}
+
+		assert.Equal(t, &snapshot.Delta{Keyframe: true, Entities: zombieAt(1)}, publishSnapshot(1))
+
+		// When
+		ack, err := newTestRegistry().Encode(protocol.JSON, protocol.NewEnvelope(protocol.TypeWorldAck, 0, &snapshot.Ack{Tick: 1}))
+		require.NoError(t, err)
+
+		client.send(t, bus.Frame{Kind: bus.FrameMessage, Message: ack})
+
+		// Frames from a client are handled in order, so the acknowledgement has been handled when the ping is answered
+		client.send(t, bus.Frame{Kind: bus.FramePing})
+		require.Equal(t, bus.FramePong, client.read(t).Kind)
+
+		// Then
+		assert.Equal(t, &snapshot.Delta{BaseTick: 1, Entities: zombieAt(2)}, publishSnapshot(2))
+		assert.Empty(t, subscriber, "acknowledgements should not be passed on")
+	})
+}
+
+// newConnector starts a Connector using an in-memory broker, and returns the broker
+func newConnector(
+	ctx context.Context,
+	t *testing.T,
+	onConnect connectors.OnConnect,
+	onDisconnect connectors.OnDisconnect,
+	subscriber chan connectors.ClientMessage,
+) *memory.Broker {
+	return newConnectorWith(ctx, t, connectorSetup{config: bus.DefaultConfig()}, onConnect, onDisconnect, subscriber)
+}
+
+// connectorSetup contains settings for newConnectorWith
+type connectorSetup struct {
+	config bus.Config
+	// wrapPublisher, if set, wraps the publisher the Connector sends frames with
+	wrapPublisher func(pubsub.Publisher) pubsub.Publisher
+}
+
+// newConnectorWith is like newConnector, but starts the Connector with the given setup
+func newConnectorWith(
+	ctx context.Context,
+	t *testing.T,
+	setup connectorSetup,
+	onConnect connectors.OnConnect,
+	onDisconnect connectors.OnDisconnect,
+	subscriber chan connectors.ClientMessage,
+) *memory.Broker {
+	broker := memory.NewBroker()
+	frames := make(chan string)
+
+	publisher := broker.NewPublisher(bus.DefaultServerTopic)
+	if setup.wrapPublisher != nil {
+		publisher = setup.wrapPublisher(publisher)
+	}
+
+	connector := bus.NewConnector(
+		ctx,
+		zap.NewNop().Sugar(),
+		setup.config,
+		publisher,
+		broker.NewConsumer(ctx, bus.DefaultClientTopic, frames),
+		subscriber,
+		newTestRegistry(),
+	)
+
+	require.NoError(t, connector.ListenForConnections(onConnect, onDisconnect))
+
+	t.Cleanup(func() {
+		assert.NoError(t, connector.StopListening())
+	})
+
+	return broker
+}
+
+// failingPublisher fails to send messages containing fail
+type failingPublisher struct {
+	pubsub.Publisher
+	fail string
+}
+
+func (p failingPublisher) SendMsg(msg string) error {
+	if strings.Contains(msg, p.fail) {
+		return errors.New("broker unavailable")
+	}
+
+	return p.Publisher.SendMsg(msg)
+}
+
+// subscribeTo returns an OnConnect subscribing clients to the broadcaster, and welcoming them
+func subscribeTo(broadcaster *broadcast.Broadcaster) connectors.OnConnect {
+	return func(_ connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+		subscribe(broadcaster)
+		return []*protocol.Envelope{message("welcome")}, nil
+	}
+}
+
+// testClient talks with the Connector through the broker, like a real client would
+type testClient struct {
+	id        string
+	publisher pubsub.Publisher
+	frames    chan string
+}
+
+// connect connects the client, and waits for the welcome message from subscribeTo. The client is subscribed once
+// welcomed, as onConnect subscribes before returning.
+func (c *testClient) connect(t *testing.T, topics ...string) {
+	c.send(t, bus.Frame{Kind: bus.FrameConnect, Version: protocol.Version, Topics: topics})
+
+	require.Equal(t, "welcome", *c.readEnvelope(t).Payload.(*string))
+}
+
+func (c *testClient) send(t *testing.T, frame bus.Frame) {
+	frame.Client = c.id
+
+	data, err := json.Marshal(frame)
+	require.NoError(t, err)
+
+	require.NoError(t, c.publisher.SendMsg(string(data)))
+}
+
+// read returns the next frame to the client
+func (c *testClient) read(t *testing.T) bus.Frame {
+	for {
+		select {
+		case data := <-c.frames:
+			var frame bus.Frame
+			require.NoError(t, json.Unmarshal([]byte(data), &frame))
+
+			if frame.Client == c.id {
+				return frame
+			}
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for frame")
+		}
+	}
+}
+
+// readEnvelope returns the message in the next frame to the client
+func (c *testClient) readEnvelope(t *testing.T) *protocol.Envelope {
+	frame := c.read(t)
+	require.Equal(t, bus.FrameMessage, frame.Kind)
+
+	envelope, err := newTestRegistry().Decode(protocol.JSON, frame.Message)
+	require.NoError(t, err)
+
+	return envelope
+}
+
+func newTestClient(ctx context.Context, broker *memory.Broker, id string) *testClient {
+	frames := make(chan string, 100)
+	consumer := broker.NewConsumer(ctx, bus.DefaultServerTopic, frames)
+
+	go func() {
+		_ = consumer.ListenForMessages()
+	}()
+
+	return &testClient{
+		id:        id,
+		publisher: broker.NewPublisher(bus.DefaultClientTopic),
+		frames:    frames,
+	}
+}
+
+// newTestRegistry returns a registry with the message type "test", which has a string payload, and the world snapshot
+// message types
+func newTestRegistry() *protocol.Registry {
+	registry := protocol.NewRegistry()
+	registry.Register("test", func() interface{} { return new(string) })
+	registry.Register(protocol.TypeWorldDelta, func() interface{} { return &snapshot.Delta{} })
+	registry.Register(protocol.TypeWorldAck, func() interface{} { return &snapshot.Ack{} })
+
+	return registry
+}
+
+// message returns a test message with the given text as payload
+func message(text string) *protocol.Envelope {
+	return protocol.NewEnvelope("test", 0, text)
+}
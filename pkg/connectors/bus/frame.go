@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"encoding/json"
+)
+
+// Default topics on the message bus
+const (
+	// DefaultClientTopic is where clients send frames to the server
+	DefaultClientTopic = "gameinit"
+	// DefaultServerTopic is where the server sends frames to clients. Every client receives the frames to all clients,
+	// and must pick out its own by the client ID.
+	DefaultServerTopic = "zombie"
+)
+
+// Kinds of frames
+const (
+	// FrameConnect is sent by a client to join the game
+	FrameConnect = "connect"
+	// FrameDisconnect is sent by a client to leave the game
+	FrameDisconnect = "disconnect"
+	// FrameMessage carries a protocol message, in either direction
+	FrameMessage = "message"
+	// FramePing is sent by a client to tell that it's still there, when it has nothing else to send. Clients that don't
+	// send any frame for Config.IdleTimeout are disconnected. The server answers with FramePong, so that clients can tell
+	// whether the server is still there.
+	FramePing = "ping"
+	// FramePong is sent by the server in answer to FramePing
+	FramePong = "pong"
+	// FrameClosed is sent by the server when it won't talk with a client anymore, for instance because the client
+	// couldn't join, fell too far behind or was idle for too long. Error tells why.
+	FrameClosed = "closed"
+)
+
+// Frame is what is sent on the message bus. Since every client shares the same topics, each frame tells which client it
+// is from or to.
+type Frame struct {
+	// Client is the ID of the client the frame is from or to. Clients pick their own IDs, which must be unique, for
+	// instance UUIDs.
+	Client string `json:"client"`
+	Kind   string `json:"kind"`
+	// Version is the protocol version the client speaks. It's only used in FrameConnect.
+	Version int `json:"version,omitempty"`
+	// Room is the ID of the room the client asks to join, see connectors.Client. It's only used in FrameConnect.
+	Room string `json:"room,omitempty"`
+	// Topics are the broadcast topics the client wants to receive. If empty, the client receives all topics. It's only
+	// used in FrameConnect.
+	Topics []string `json:"topics,omitempty"`
+	// Message is a protocol.Envelope encoded with protocol.JSON. It's only used in FrameMessage.
+	Message json.RawMessage `json:"message,omitempty"`
+	// Error tells why the server closed the connection. It's only used in FrameClosed.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,160 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/connectors/bus"
+)
+
+const (
+	defaultBroker       = "localhost:9092"
+	defaultDialTimeout  = 10 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
+// SASL mechanisms
+const (
+	SASLPlain       = "plain"
+	SASLScramSHA256 = "scram-sha-256"
+	SASLScramSHA512 = "scram-sha-512"
+)
+
+// Config contains settings for connecting to Kafka
+type Config struct {
+	// Brokers are the addresses of the brokers to bootstrap from, for instance localhost:9092
+	Brokers []string
+	// ClientTopic is the topic clients send frames to, see package bus
+	ClientTopic string
+	// ServerTopic is the topic frames are sent to clients on
+	ServerTopic string
+	// ClientIdleTimeout is how long clients can go without sending a frame before they are disconnected, see
+	// bus.Config.IdleTimeout
+	ClientIdleTimeout time.Duration
+	// Clock is used for waiting before retrying, and for timing out idle clients. If nil, the real wall clock is used.
+	Clock clock.Clock
+	// GroupID is the consumer group. If empty, the server consumes without a group, starting with the newest message.
+	GroupID string
+	// DialTimeout is the time to wait for a connection to a broker
+	DialTimeout time.Duration
+	// WriteTimeout is the time to wait for a message to be written
+	WriteTimeout time.Duration
+	TLS          TLSConfig
+	SASL         SASLConfig
+}
+
+// TLSConfig contains settings for connecting to Kafka with TLS
+type TLSConfig struct {
+	Enabled bool
+	// CAFile is a PEM file with the certificate authorities to trust. If empty, the system's are trusted.
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate, if the broker authenticates clients with TLS
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify turns off verification of the broker's certificate. Don't use it outside development.
+	InsecureSkipVerify bool
+}
+
+// SASLConfig contains settings for authenticating with SASL
+type SASLConfig struct {
+	// Mechanism is SASLPlain, SASLScramSHA256 or SASLScramSHA512. If empty, SASL isn't used.
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// DefaultConfig returns the default Config, which is for the broker in docker-compose-kafka.yaml
+func DefaultConfig() Config {
+	return Config{
+		Brokers:           []string{defaultBroker},
+		ClientTopic:       bus.DefaultClientTopic,
+		ServerTopic:       bus.DefaultServerTopic,
+		ClientIdleTimeout: bus.DefaultIdleTimeout,
+		DialTimeout:       defaultDialTimeout,
+		WriteTimeout:      defaultWriteTimeout,
+	}
+}
+
+// dialer returns a Dialer connecting to brokers as configured
+func (c Config) dialer() (*kafka.Dialer, error) {
+	if len(c.Brokers) == 0 {
+		return nil, fmt.Errorf("no brokers configured")
+	}
+
+	tlsConfig, err := c.TLS.config()
+	if err != nil {
+		return nil, fmt.Errorf("creating TLS config: %w", err)
+	}
+
+	mechanism, err := c.SASL.mechanism()
+	if err != nil {
+		return nil, fmt.Errorf("creating SASL mechanism: %w", err)
+	}
+
+	return &kafka.Dialer{
+		Timeout:       c.DialTimeout,
+		DualStack:     true,
+		TLS:           tlsConfig,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+// config returns the tls.Config to connect with, or nil if TLS isn't enabled
+func (c TLSConfig) config() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// mechanism returns the SASL mechanism to authenticate with, or nil if SASL isn't used
+func (c SASLConfig) mechanism() (sasl.Mechanism, error) {
+	switch strings.ToLower(c.Mechanism) {
+	case "":
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %q, must be %s, %s or %s", c.Mechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+}
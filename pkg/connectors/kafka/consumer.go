@@ -2,11 +2,15 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"time"
+
 	"github.com/segmentio/kafka-go"
+	"github.com/yngvark/gr-zombie/pkg/clock"
 	"github.com/yngvark/gr-zombie/pkg/pubsub"
 	"go.uber.org/zap"
-	"time"
 )
 
 type kafkaConsumer struct {
@@ -14,27 +18,69 @@ type kafkaConsumer struct {
 	ctx        context.Context
 	subscriber chan string
 	reader     *kafka.Reader
+	clock      clock.Clock
+	// commit is true when consuming in a consumer group, which keeps track of the messages that have been consumed
+	commit bool
 }
 
+// ListenForMessages reads messages from Kafka. This function blocks until the context provided on creation is done, or
+// the consumer is closed. Reading is retried if it fails. In a consumer group, a message is committed once it has been
+// passed on to the subscriber channel, so that it is read again by the group if the server stops before then.
 func (c kafkaConsumer) ListenForMessages() error {
-	for i := 0; i < 10; i++ {
-		msg, err := c.reader.ReadMessage(c.ctx)
-		if err != nil {
-			return fmt.Errorf("reading message. Failed: %w", err)
+	defer c.log.Info("Kafka reading done")
+
+	backoff := pubsub.NewBackoff(c.clock)
+
+	for {
+		msg, err := c.reader.FetchMessage(c.ctx)
+
+		switch {
+		case c.ctx.Err() != nil, errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			c.log.Errorf("Reading message from Kafka, retrying: %s", err.Error())
+
+			if !backoff.Wait(c.ctx) {
+				return nil
+			}
+
+			continue
 		}
 
-		fmt.Printf("message: %s\n", string(msg.Value))
-		c.subscriber <- string(msg.Value)
+		backoff.Reset()
 
-		if string(msg.Value) == "/quit" {
-			fmt.Println("Got /quit msg, quitting")
-			break
+		select {
+		case c.subscriber <- string(msg.Value):
+		case <-c.ctx.Done():
+			return nil
+		}
+
+		if c.commit && !c.commitMessage(msg) {
+			return nil
 		}
 	}
+}
 
-	c.log.Info("Kafka reading done")
+// commitMessage commits a message, retrying until it succeeds. It returns false if the consumer stops before then.
+func (c kafkaConsumer) commitMessage(msg kafka.Message) bool {
+	backoff := pubsub.NewBackoff(c.clock)
 
-	return nil
+	for {
+		err := c.reader.CommitMessages(c.ctx, msg)
+
+		switch {
+		case err == nil:
+			return true
+		case c.ctx.Err() != nil, errors.Is(err, io.ErrClosedPipe):
+			return false
+		}
+
+		c.log.Errorf("Committing message at offset %d, retrying: %s", msg.Offset, err.Error())
+
+		if !backoff.Wait(c.ctx) {
+			return false
+		}
+	}
 }
 
 func (c kafkaConsumer) SubscriberChannel() chan string {
@@ -45,25 +91,45 @@ func (c kafkaConsumer) Close() error {
 	return c.reader.Close()
 }
 
-// NewConsumer returns a KAFKA consumer
+// NewConsumer returns a KAFKA consumer. Messages sent before the game started are for a previous game, so a consumer
+// without a consumer group starts with the next message, and so does a new consumer group.
 func NewConsumer(
 	ctx context.Context,
 	logger *zap.SugaredLogger,
+	config Config,
 	topic string,
 	subscriber chan string,
 ) (pubsub.Consumer, error) {
+	dialer, err := config.dialer()
+	if err != nil {
+		return nil, err
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  []string{"localhost:9092"},
-		Topic:    topic,
-		MinBytes: 1,
-		MaxBytes: 10e6,
-		MaxWait:  100 * time.Millisecond,
+		Brokers:     config.Brokers,
+		GroupID:     config.GroupID,
+		Topic:       topic,
+		Dialer:      dialer,
+		StartOffset: kafka.LastOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     100 * time.Millisecond,
 	})
 
+	if config.GroupID == "" {
+		// StartOffset only applies to consumer groups
+		err = reader.SetOffset(kafka.LastOffset)
+		if err != nil {
+			return nil, fmt.Errorf("setting offset: %w", err)
+		}
+	}
+
 	return kafkaConsumer{
 		log:        logger,
 		ctx:        ctx,
 		subscriber: subscriber,
 		reader:     reader,
+		clock:      config.Clock,
+		commit:     config.GroupID != "",
 	}, nil
 }
@@ -0,0 +1,104 @@
+package kafka_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/connectors/kafka"
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/pubsubtest"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKafka needs a running Kafka broker, which can be started with docker-compose-kafka.yaml. It is skipped unless
+// GAME_TEST_KAFKA is set, see make test-kafka.
+func TestKafka(t *testing.T) { //nolint:funlen
+	if os.Getenv("GAME_TEST_KAFKA") == "" {
+		t.Skip("GAME_TEST_KAFKA not set")
+	}
+
+	t.Run("Should follow the Consumer contract without a consumer group", func(t *testing.T) {
+		pubsubtest.TestConsumer(t, newPubSub(kafka.DefaultConfig()))
+	})
+
+	t.Run("Should follow the Consumer contract in a consumer group", func(t *testing.T) {
+		config := kafka.DefaultConfig()
+		config.GroupID = fmt.Sprintf("test-%d", time.Now().UnixNano())
+
+		pubsubtest.TestConsumer(t, newPubSub(config))
+	})
+
+	t.Run("Should not pass on committed messages again when the consumer group restarts", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
+		defer cancelFn()
+
+		config := kafka.DefaultConfig()
+		config.GroupID = fmt.Sprintf("test-%d", time.Now().UnixNano())
+		topic := fmt.Sprintf("test-%d", time.Now().UnixNano())
+		logger := zap.NewNop().Sugar()
+
+		publisher, err := kafka.NewPublisher(ctx, logger, config, topic)
+		require.NoError(t, err)
+
+		defer func() { assert.NoError(t, publisher.Close()) }()
+
+		firstCtx, stopFirst := context.WithCancel(ctx)
+		defer stopFirst()
+
+		first, err := kafka.NewConsumer(firstCtx, logger, config, topic, make(chan string))
+		require.NoError(t, err)
+
+		stopped := pubsubtest.Listen(ctx, t, publisher, first)
+
+		require.NoError(t, publisher.SendMsg("first"))
+		require.NoError(t, publisher.SendMsg("second"))
+
+		// A message is committed before the next one is passed on, so "first" is committed once "second" is received
+		require.Equal(t, "first", pubsubtest.Receive(ctx, t, first))
+		require.Equal(t, "second", pubsubtest.Receive(ctx, t, first))
+
+		stopFirst()
+		pubsubtest.WaitForStop(t, stopped)
+		require.NoError(t, first.Close())
+
+		// When
+		require.NoError(t, publisher.SendMsg("third"))
+
+		second, err := kafka.NewConsumer(ctx, logger, config, topic, make(chan string))
+		require.NoError(t, err)
+
+		defer func() { assert.NoError(t, second.Close()) }()
+
+		go func() { assert.NoError(t, second.ListenForMessages()) }()
+
+		// Then
+		// "second" may be passed on again, as the first consumer may have stopped before committing it
+		for msg := ""; msg != "third"; {
+			msg = pubsubtest.Receive(ctx, t, second)
+			assert.NotEqual(t, "first", msg)
+		}
+	})
+}
+
+// newPubSub returns a pubsubtest.NewPubSub publishing to and consuming a new topic
+func newPubSub(config kafka.Config) pubsubtest.NewPubSub {
+	return func(ctx context.Context, t *testing.T, subscriber chan string) (pubsub.Publisher, pubsub.Consumer) {
+		topic := fmt.Sprintf("test-%d", time.Now().UnixNano())
+		logger := zap.NewNop().Sugar()
+
+		publisher, err := kafka.NewPublisher(ctx, logger, config, topic)
+		require.NoError(t, err)
+
+		consumer, err := kafka.NewConsumer(ctx, logger, config, topic, subscriber)
+		require.NoError(t, err)
+
+		return publisher, consumer
+	}
+}
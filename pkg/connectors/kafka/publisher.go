@@ -4,25 +4,30 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/segmentio/kafka-go"
 	"github.com/yngvark/gr-zombie/pkg/pubsub"
 	"go.uber.org/zap"
-	"time"
 )
 
 type kafkaPublisher struct {
-	log      *zap.SugaredLogger
-	ctx      context.Context
-	cancelFn context.CancelFunc
-	conn     *kafka.Conn
+	log          *zap.SugaredLogger
+	ctx          context.Context
+	conn         *kafka.Conn
+	writeTimeout time.Duration
 }
 
 func (p kafkaPublisher) SendMsg(msg string) error {
-	_, err := p.conn.WriteMessages(kafka.Message{
+	err := p.conn.SetWriteDeadline(time.Now().Add(p.writeTimeout))
+	if err != nil {
+		return fmt.Errorf("setting write deadline: %w", err)
+	}
+
+	_, err = p.conn.WriteMessages(kafka.Message{
 		Value: []byte(msg),
 	})
 	if err != nil {
-		p.cancelFn()
 		return fmt.Errorf("sending message: %w", err)
 	}
 
@@ -33,30 +38,38 @@ func (p kafkaPublisher) Close() error {
 	return p.conn.Close()
 }
 
-// NewPublisher returns a kafka publisher
+// NewPublisher returns a kafka publisher. It connects to the leader of the topic through the first of the configured
+// brokers that answers.
 func NewPublisher(
 	ctx context.Context,
-	cancelFn context.CancelFunc,
 	logger *zap.SugaredLogger,
+	config Config,
 	topic string,
 ) (pubsub.Publisher, error) {
-	config := kafka.ReaderConfig{
-		Brokers:  []string{"localhost:9092"},
-		Topic:    topic,
-		MinBytes: 1,
-		MaxBytes: 10e6,
-		MaxWait:  100 * time.Millisecond,
+	dialer, err := config.dialer()
+	if err != nil {
+		return nil, err
+	}
+
+	var conn *kafka.Conn
+
+	for _, broker := range config.Brokers {
+		conn, err = dialer.DialLeader(ctx, "tcp", broker, topic, 0)
+		if err == nil {
+			break
+		}
+
+		logger.Infof("Could not connect to Kafka through broker %s: %s", broker, err.Error())
 	}
 
-	conn, err := kafka.DialLeader(ctx, "tcp", "localhost:9092", config.Topic, config.Partition)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to Kafka: %w", err)
 	}
 
 	return kafkaPublisher{
-		log:      logger,
-		ctx:      ctx,
-		cancelFn: cancelFn,
-		conn:     conn,
+		log:          logger,
+		ctx:          ctx,
+		conn:         conn,
+		writeTimeout: config.WriteTimeout,
 	}, nil
 }
@@ -0,0 +1,115 @@
+package pulsar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/connectors/bus"
+)
+
+const (
+	defaultURL              = "pulsar://localhost:36650"
+	defaultSubscriptionName = "gr-zombie"
+	timeoutsDefault         = 30 * time.Second
+)
+
+// Config contains settings for connecting to Pulsar
+type Config struct {
+	// URL is the service URL of the Pulsar cluster, for instance pulsar://localhost:6650, or pulsar+ssl://... for TLS
+	URL string
+	// ClientTopic is the topic clients send frames to, see package bus
+	ClientTopic string
+	// ServerTopic is the topic frames are sent to clients on
+	ServerTopic string
+	// ClientIdleTimeout is how long clients can go without sending a frame before they are disconnected, see
+	// bus.Config.IdleTimeout
+	ClientIdleTimeout time.Duration
+	// Clock is used for waiting before retrying, and for timing out idle clients. If nil, the real wall clock is used.
+	Clock clock.Clock
+	// SubscriptionName is the name of the subscription to ClientTopic
+	SubscriptionName string
+	// SubscriptionType decides how messages are shared between consumers with the same subscription
+	SubscriptionType pulsar.SubscriptionType
+	// ConnectionTimeout is the time to wait for a connection to be established
+	ConnectionTimeout time.Duration
+	// OperationTimeout is the time to wait for operations like creating producers and consumers
+	OperationTimeout time.Duration
+	TLS              TLSConfig
+	// AuthToken is a token to authenticate with. If empty, token authentication isn't used.
+	AuthToken string
+}
+
+// TLSConfig contains settings for connecting to Pulsar with TLS. TLS is used when the URL is a pulsar+ssl:// URL.
+type TLSConfig struct {
+	// TrustCertsFile is a PEM file with the certificate authorities to trust
+	TrustCertsFile string
+	// AllowInsecureConnection accepts untrusted certificates from the broker. Don't use it outside development.
+	AllowInsecureConnection bool
+	// ValidateHostname checks that the broker's certificate is for the broker's hostname
+	ValidateHostname bool
+	// CertFile and KeyFile are the PEM files of the client certificate, if the broker authenticates clients with TLS
+	CertFile string
+	KeyFile  string
+}
+
+// DefaultConfig returns the default Config, which is for the broker in docker-compose-pulsar.yaml
+func DefaultConfig() Config {
+	return Config{
+		URL:               defaultURL,
+		ClientTopic:       bus.DefaultClientTopic,
+		ServerTopic:       bus.DefaultServerTopic,
+		ClientIdleTimeout: bus.DefaultIdleTimeout,
+		SubscriptionName:  defaultSubscriptionName,
+		SubscriptionType:  pulsar.Exclusive,
+		ConnectionTimeout: timeoutsDefault,
+		OperationTimeout:  timeoutsDefault,
+	}
+}
+
+// ParseSubscriptionType returns the subscription type with the given name, which is exclusive, shared, failover or
+// key_shared
+func ParseSubscriptionType(name string) (pulsar.SubscriptionType, error) {
+	switch strings.ToLower(name) {
+	case "exclusive":
+		return pulsar.Exclusive, nil
+	case "shared":
+		return pulsar.Shared, nil
+	case "failover":
+		return pulsar.Failover, nil
+	case "key_shared":
+		return pulsar.KeyShared, nil
+	default:
+		return 0, fmt.Errorf("unknown subscription type %q, must be exclusive, shared, failover or key_shared", name)
+	}
+}
+
+// newClient returns a client connecting to Pulsar as configured
+func (c Config) newClient() (pulsar.Client, error) {
+	options := pulsar.ClientOptions{
+		URL:                        c.URL,
+		ConnectionTimeout:          c.ConnectionTimeout,
+		OperationTimeout:           c.OperationTimeout,
+		TLSTrustCertsFilePath:      c.TLS.TrustCertsFile,
+		TLSAllowInsecureConnection: c.TLS.AllowInsecureConnection,
+		TLSValidateHostname:        c.TLS.ValidateHostname,
+	}
+
+	switch {
+	case c.AuthToken != "" && c.TLS.CertFile != "":
+		return nil, fmt.Errorf("can authenticate with either a token or a TLS client certificate, not both")
+	case c.AuthToken != "":
+		options.Authentication = pulsar.NewAuthenticationToken(c.AuthToken)
+	case c.TLS.CertFile != "":
+		options.Authentication = pulsar.NewAuthenticationTLS(c.TLS.CertFile, c.TLS.KeyFile)
+	}
+
+	client, err := pulsar.NewClient(options)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate Pulsar client: %w", err)
+	}
+
+	return client, nil
+}
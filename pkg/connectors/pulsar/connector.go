@@ -0,0 +1,33 @@
+package pulsar
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yngvark/gr-zombie/pkg/connectors"
+	"github.com/yngvark/gr-zombie/pkg/connectors/bus"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"go.uber.org/zap"
+)
+
+// NewConnector returns a Connector that talks with clients through Pulsar, see package bus
+func NewConnector(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	config Config,
+	subscriber chan connectors.ClientMessage,
+	registry *protocol.Registry,
+) (connectors.Connector, error) {
+	publisher, err := NewPublisher(ctx, logger, config, config.ServerTopic)
+	if err != nil {
+		return nil, fmt.Errorf("creating publisher: %w", err)
+	}
+
+	consumer, err := NewConsumer(ctx, logger, config, config.ClientTopic, make(chan string))
+	if err != nil {
+		_ = publisher.Close()
+		return nil, fmt.Errorf("creating consumer: %w", err)
+	}
+
+	return bus.NewConnector(ctx, logger, bus.Config{IdleTimeout: config.ClientIdleTimeout, Clock: config.Clock}, publisher, consumer, subscriber, registry), nil
+}
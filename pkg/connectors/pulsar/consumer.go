@@ -3,10 +3,11 @@ package pulsar
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/yngvark/gr-zombie/pkg/clock"
 	"github.com/yngvark/gr-zombie/pkg/pubsub"
 	"go.uber.org/zap"
 )
@@ -17,23 +18,47 @@ type pulsarConsumer struct {
 	subscriber chan string
 	client     pulsar.Client
 	consumer   pulsar.Consumer
+	clock      clock.Clock
 }
 
 func (c *pulsarConsumer) SubscriberChannel() chan string {
 	return c.subscriber
 }
 
-// ListenForMessages reads messages from Pulsar. This function blocks until the context provided on creation is done.
+// ListenForMessages reads messages from Pulsar. This function blocks until the context provided on creation is done, or
+// the consumer is closed. Receiving is retried if it fails. A message is acknowledged once it has been passed on to the
+// subscriber channel. If the context is done before then, it is negatively acknowledged, so that it is redelivered.
 func (c *pulsarConsumer) ListenForMessages() error {
-	select {
-	case msg := <-c.consumer.Chan():
-		msgString := string(msg.Payload())
-		c.subscriber <- msgString
-	case <-c.ctx.Done():
-		return nil
-	}
+	defer c.log.Info("Pulsar reading done")
 
-	return nil
+	backoff := pubsub.NewBackoff(c.clock)
+
+	for {
+		msg, err := c.consumer.Receive(c.ctx)
+
+		switch {
+		case c.ctx.Err() != nil, errors.Is(err, pulsar.ErrConsumerClosed):
+			return nil
+		case err != nil:
+			c.log.Errorf("Receiving message from Pulsar, retrying: %s", err.Error())
+
+			if !backoff.Wait(c.ctx) {
+				return nil
+			}
+
+			continue
+		}
+
+		backoff.Reset()
+
+		select {
+		case c.subscriber <- string(msg.Payload()):
+			c.consumer.Ack(msg)
+		case <-c.ctx.Done():
+			c.consumer.Nack(msg)
+			return nil
+		}
+	}
 }
 
 func (c *pulsarConsumer) Close() error {
@@ -45,32 +70,26 @@ func (c *pulsarConsumer) Close() error {
 	return nil
 }
 
-const timeoutsDefault = 30 * time.Second
-
-// NewConsumer returns a pulsar consumer
+// NewConsumer returns a pulsar consumer, subscribing to the topic with the configured subscription
 func NewConsumer(
 	ctx context.Context,
 	logger *zap.SugaredLogger,
+	config Config,
 	topic string,
 	subscriber chan string,
 ) (pubsub.Consumer, error) {
-	// Create client
-	client, err := pulsar.NewClient(pulsar.ClientOptions{
-		URL:               "pulsar://localhost:36650",
-		OperationTimeout:  timeoutsDefault,
-		ConnectionTimeout: timeoutsDefault,
-	})
+	client, err := config.newClient()
 	if err != nil {
-		return nil, fmt.Errorf("could not instantiate Pulsar client: %w", err)
+		return nil, err
 	}
 
-	// Create consumer
 	consumer, err := client.Subscribe(pulsar.ConsumerOptions{
 		Topic:            topic,
-		SubscriptionName: "mysub2",
-		Type:             pulsar.Exclusive,
+		SubscriptionName: config.SubscriptionName,
+		Type:             config.SubscriptionType,
 	})
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("subscribing to client: %w", err)
 	}
 
@@ -80,6 +99,7 @@ func NewConsumer(
 		client:     client,
 		consumer:   consumer,
 		subscriber: subscriber,
+		clock:      config.Clock,
 	}
 
 	return c, nil
@@ -12,7 +12,6 @@ import (
 type pulsarPublisher struct {
 	log      *zap.SugaredLogger
 	ctx      context.Context
-	cancelFn context.CancelFunc
 	client   pulsar.Client
 	producer pulsar.Producer
 }
@@ -22,7 +21,6 @@ func (m *pulsarPublisher) SendMsg(msg string) error {
 		Payload: []byte(msg),
 	})
 	if err != nil {
-		m.cancelFn()
 		return fmt.Errorf("sending message: %w", err)
 	}
 
@@ -40,32 +38,26 @@ func (m *pulsarPublisher) Close() error {
 // NewPublisher returns a pulsar publisher
 func NewPublisher(
 	ctx context.Context,
-	cancelFn context.CancelFunc,
 	logger *zap.SugaredLogger,
+	config Config,
 	topic string,
 ) (pubsub.Publisher, error) {
-	// Create client
-	client, err := pulsar.NewClient(pulsar.ClientOptions{
-		URL:               "pulsar://localhost:36650",
-		OperationTimeout:  timeoutsDefault,
-		ConnectionTimeout: timeoutsDefault,
-	})
+	client, err := config.newClient()
 	if err != nil {
-		return nil, fmt.Errorf("could not instantiate Pulsar client: %w", err)
+		return nil, err
 	}
 
-	// Create producer
 	producer, err := client.CreateProducer(pulsar.ProducerOptions{
 		Topic: topic,
 	})
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("could not create producer: %w", err)
 	}
 
 	p := &pulsarPublisher{
 		log:      logger,
 		ctx:      ctx,
-		cancelFn: cancelFn,
 		client:   client,
 		producer: producer,
 	}
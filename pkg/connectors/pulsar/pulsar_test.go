@@ -0,0 +1,81 @@
+package pulsar_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/connectors/pulsar"
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/pubsubtest"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPulsar needs a running Pulsar broker, which can be started with docker-compose-pulsar.yaml. It is skipped unless
+// GAME_TEST_PULSAR is set, see make test-pulsar.
+func TestPulsar(t *testing.T) { //nolint:funlen
+	if os.Getenv("GAME_TEST_PULSAR") == "" {
+		t.Skip("GAME_TEST_PULSAR not set")
+	}
+
+	t.Run("Should follow the Consumer contract", func(t *testing.T) {
+		pubsubtest.TestConsumer(t, func(ctx context.Context, t *testing.T, subscriber chan string) (pubsub.Publisher, pubsub.Consumer) {
+			topic := fmt.Sprintf("test-%d", time.Now().UnixNano())
+			logger := zap.NewNop().Sugar()
+
+			publisher, err := pulsar.NewPublisher(ctx, logger, pulsar.DefaultConfig(), topic)
+			require.NoError(t, err)
+
+			consumer, err := pulsar.NewConsumer(ctx, logger, pulsar.DefaultConfig(), topic, subscriber)
+			require.NoError(t, err)
+
+			return publisher, consumer
+		})
+	})
+
+	t.Run("Should deliver a message again when the consumer stops before passing it on", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
+		defer cancelFn()
+
+		config := pulsar.DefaultConfig()
+		config.SubscriptionName = fmt.Sprintf("test-%d", time.Now().UnixNano())
+		topic := fmt.Sprintf("test-%d", time.Now().UnixNano())
+		logger := zap.NewNop().Sugar()
+
+		publisher, err := pulsar.NewPublisher(ctx, logger, config, topic)
+		require.NoError(t, err)
+
+		defer func() { assert.NoError(t, publisher.Close()) }()
+
+		firstCtx, stopFirst := context.WithCancel(ctx)
+		defer stopFirst()
+
+		first, err := pulsar.NewConsumer(firstCtx, logger, config, topic, make(chan string))
+		require.NoError(t, err)
+
+		stopped := pubsubtest.Listen(ctx, t, publisher, first)
+		require.NoError(t, publisher.SendMsg("unread"))
+
+		// When
+		// Nobody reads the subscriber channel, so "unread" isn't acknowledged
+		stopFirst()
+		pubsubtest.WaitForStop(t, stopped)
+		require.NoError(t, first.Close())
+
+		second, err := pulsar.NewConsumer(ctx, logger, config, topic, make(chan string))
+		require.NoError(t, err)
+
+		defer func() { assert.NoError(t, second.Close()) }()
+
+		go func() { assert.NoError(t, second.ListenForMessages()) }()
+
+		// Then
+		assert.Equal(t, "unread", pubsubtest.Receive(ctx, t, second))
+	})
+}
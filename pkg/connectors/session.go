@@ -0,0 +1,91 @@
+package connectors
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+)
+
+// Session is what a Connector keeps track of for a connected client: its subscription to broadcasts, the sequence
+// numbers of the messages sent to it, and the world snapshots it has acknowledged.
+type Session struct {
+	// name identifies the client's subscription in the broadcaster's logs and statistics
+	name     string
+	topics   []string
+	registry *protocol.Registry
+	codec    protocol.Codec
+	// broadcaster is the broadcaster the client is subscribed to, once subscribed
+	broadcaster *broadcast.Broadcaster
+	// subscription is the client's subscription to broadcaster
+	subscription *broadcast.Subscriber
+	// deltas turns world snapshots into deltas from what the client has acknowledged
+	deltas *snapshot.Encoder
+	// seq is the sequence number of the last message sent to the client
+	seq uint64
+}
+
+// Subscribe subscribes the client to a broadcaster, replacing any previous subscription. Connectors pass it to
+// OnConnect.
+func (s *Session) Subscribe(broadcaster *broadcast.Broadcaster) {
+	s.Unsubscribe()
+
+	s.broadcaster = broadcaster
+	s.subscription = broadcaster.AddSubscriber(s.name, s.topics...)
+}
+
+// Unsubscribe removes the client's subscription to broadcasts, if any
+func (s *Session) Unsubscribe() {
+	if s.subscription != nil {
+		s.broadcaster.RemoveSubscriber(s.subscription)
+	}
+}
+
+// Subscription returns the client's subscription to broadcasts, or nil if the client hasn't subscribed
+func (s *Session) Subscription() *broadcast.Subscriber {
+	return s.subscription
+}
+
+// Encode encodes a message to the client, numbered with the client's next sequence number. World snapshots are encoded
+// as deltas from the last snapshot the client acknowledged. Encode must not be called concurrently with itself.
+func (s *Session) Encode(msg *protocol.Envelope) ([]byte, error) {
+	if snap, ok := msg.Payload.(*snapshot.Snapshot); ok {
+		msg = msg.WithPayload(s.deltas.Delta(snap))
+	}
+
+	data, err := s.registry.Encode(s.codec, msg.WithSeq(s.seq+1))
+	if err != nil {
+		return nil, err
+	}
+
+	s.seq++
+
+	return data, nil
+}
+
+// Decode decodes a message from the client. Acknowledgements of world snapshots are handled by the session, and Decode
+// returns false for them, as they aren't meant for the game.
+func (s *Session) Decode(data []byte) (*protocol.Envelope, bool, error) {
+	envelope, err := s.registry.Decode(s.codec, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if ack, ok := envelope.Payload.(*snapshot.Ack); ok {
+		s.deltas.Ack(ack.Tick)
+		return nil, false, nil
+	}
+
+	return envelope, true, nil
+}
+
+// NewSession returns a Session for a client receiving the given broadcast topics, or all topics if none are given.
+// Messages to and from the client are encoded with the codec.
+func NewSession(name string, topics []string, registry *protocol.Registry, codec protocol.Codec) *Session {
+	return &Session{
+		name:     name,
+		topics:   topics,
+		registry: registry,
+		codec:    codec,
+		deltas:   snapshot.NewEncoder(),
+	}
+}
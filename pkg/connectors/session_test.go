@@ -0,0 +1,100 @@
+package connectors_test
+
+import (
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/connectors"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	t.Run("Should number messages, and encode snapshots as deltas from the last acknowledged snapshot", func(t *testing.T) {
+		// Given
+		registry := newTestRegistry()
+		session := connectors.NewSession("a", nil, registry, protocol.JSON)
+
+		zombieAt := func(x int) []snapshot.Entity {
+			return []snapshot.Entity{{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: "1"}, X: x}}
+		}
+
+		encode := func(tick uint64) *protocol.Envelope {
+			data, err := session.Encode(protocol.NewEnvelope(protocol.TypeWorldDelta, tick, snapshot.New(tick, false, zombieAt(int(tick)))))
+			require.NoError(t, err)
+
+			envelope, err := registry.Decode(protocol.JSON, data)
+			require.NoError(t, err)
+
+			return envelope
+		}
+
+		first := encode(1)
+
+		// When
+		ack, err := registry.Encode(protocol.JSON, protocol.NewEnvelope(protocol.TypeWorldAck, 0, &snapshot.Ack{Tick: 1}))
+		require.NoError(t, err)
+
+		_, forGame, err := session.Decode(ack)
+		require.NoError(t, err)
+
+		second := encode(2)
+
+		// Then
+		assert.False(t, forGame, "acknowledgements should be handled by the session")
+		assert.Equal(t, []uint64{1, 2}, []uint64{first.Seq, second.Seq})
+		assert.Equal(t, &snapshot.Delta{Keyframe: true, Entities: zombieAt(1)}, first.Payload)
+		assert.Equal(t, &snapshot.Delta{BaseTick: 1, Entities: zombieAt(2)}, second.Payload)
+	})
+
+	t.Run("Should decode other messages for the game", func(t *testing.T) {
+		// Given
+		registry := newTestRegistry()
+		session := connectors.NewSession("a", nil, registry, protocol.JSON)
+
+		data, err := registry.Encode(protocol.JSON, protocol.NewEnvelope("test", 0, "hi"))
+		require.NoError(t, err)
+
+		// When
+		envelope, forGame, err := session.Decode(data)
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, forGame)
+		assert.Equal(t, "hi", *envelope.Payload.(*string))
+	})
+
+	t.Run("Should replace the subscription when subscribing again, and remove it when unsubscribing", func(t *testing.T) {
+		// Given
+		first, second := broadcast.New(nil), broadcast.New(nil)
+		session := connectors.NewSession("a", []string{broadcast.TopicWorld}, newTestRegistry(), protocol.JSON)
+
+		session.Subscribe(first)
+		firstSubscription := session.Subscription()
+
+		// When
+		session.Subscribe(second)
+
+		// Then
+		<-firstSubscription.Done()
+		assert.NotSame(t, firstSubscription, session.Subscription())
+		assert.Equal(t, []string{broadcast.TopicWorld}, session.Subscription().Topics())
+
+		session.Unsubscribe()
+		<-session.Subscription().Done()
+	})
+}
+
+// newTestRegistry returns a registry with the message type "test", which has a string payload, and the world snapshot
+// message types
+func newTestRegistry() *protocol.Registry {
+	registry := protocol.NewRegistry()
+	registry.Register("test", func() interface{} { return new(string) })
+	registry.Register(protocol.TypeWorldDelta, func() interface{} { return &snapshot.Delta{} })
+	registry.Register(protocol.TypeWorldAck, func() interface{} { return &snapshot.Ack{} })
+
+	return registry
+}
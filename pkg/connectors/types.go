@@ -2,13 +2,39 @@
 // Kafka.
 package connectors
 
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+)
+
 // Connector is used to connect to clients. Implementors can use websockets, pulsar, kafka, etc.
 type Connector interface {
 	// ListenForConnections listens for incoming connections. It may or may not block, see implementation comments.
-	ListenForConnections(OnConnect) error
+	ListenForConnections(OnConnect, OnDisconnect) error
 	// CloseConnection closes the Connector
 	StopListening() error
 }
 
-// OnConnect is a function that is called when a client connects
-type OnConnect func(messagesToClientChannel chan string) error
+// Client is a client that connected
+type Client struct {
+	// ID identifies the client for as long as it is connected
+	ID string
+	// Room is the ID of the room the client asked to join. It is empty if the client didn't ask for a room.
+	Room string
+}
+
+// OnConnect is a function that is called when a client connects. It returns the messages to send to the client first,
+// typically the current state of the game.
+// subscribe subscribes the client to a broadcaster, and must be called before OnConnect returns. Broadcasts after the
+// call are sent to the client after the returned messages, so calling it at the same moment the returned state is
+// captured makes the client miss nothing and receive nothing twice.
+type OnConnect func(client Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error)
+
+// OnDisconnect is a function that is called when a client disconnects
+type OnDisconnect func(clientID string)
+
+// ClientMessage is a message received from a client
+type ClientMessage struct {
+	ClientID string
+	Message  *protocol.Envelope
+}
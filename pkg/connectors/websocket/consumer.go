@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/yngvark/gr-zombie/pkg/connectors"
-	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
 	"net/http"
 
 	"github.com/yngvark/gr-zombie/pkg/connectors/websocket/httphandler"
@@ -14,15 +14,15 @@ import (
 type connctionHandler struct {
 	ctx        context.Context
 	log        *zap.SugaredLogger
-	subscriber chan string
+	subscriber chan connectors.ClientMessage
 
 	listening          bool
-	broadcaster        *broadcast.Broadcaster
+	registry           *protocol.Registry
 	allowedCorsOrigins map[string]bool
 }
 
 // ListenForConnections starts to receive messages which will be available by reading SubscriberChannel().
-func (c *connctionHandler) ListenForConnections(onConnect connectors.OnConnect) error {
+func (c *connctionHandler) ListenForConnections(onConnect connectors.OnConnect, onDisconnect connectors.OnDisconnect) error {
 	if !c.listening {
 		c.listening = true
 	} else {
@@ -31,7 +31,7 @@ func (c *connctionHandler) ListenForConnections(onConnect connectors.OnConnect)
 
 	http.HandleFunc(
 		"/zombie",
-		httphandler.New(c.ctx, c.log, c.allowedCorsOrigins, onConnect, c.subscriber, c.broadcaster),
+		httphandler.New(c.ctx, c.log, c.allowedCorsOrigins, onConnect, onDisconnect, c.subscriber, c.registry),
 	)
 
 	return nil
@@ -46,15 +46,15 @@ func (c *connctionHandler) StopListening() error {
 func NewConnector(
 	ctx context.Context,
 	logger *zap.SugaredLogger,
-	subscriber chan string,
+	subscriber chan connectors.ClientMessage,
 	allowedCorsOrigins map[string]bool,
-	broadcaster *broadcast.Broadcaster,
+	registry *protocol.Registry,
 ) connectors.Connector {
 	return &connctionHandler{
 		ctx:                ctx,
 		log:                logger,
 		subscriber:         subscriber,
-		broadcaster:        broadcaster,
+		registry:           registry,
 		allowedCorsOrigins: allowedCorsOrigins,
 	}
 }
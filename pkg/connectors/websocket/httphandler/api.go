@@ -2,29 +2,54 @@ package httphandler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/gorilla/websocket"
 	"github.com/yngvark/gr-zombie/pkg/connectors"
-	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // New returns a HTTP handler that handles incoming websocket connections
 // context is used to disconnect clients when the caller decides it's time to stop.
-// subscriber is used to for parent callers to push messages to. These messages will be sent to the websocket.
+// subscriber receives the messages clients send, tagged with the ID of the client. Each connected client gets a unique
+// ID, which is passed to onConnect and onDisconnect.
+// registry is used for encoding messages to clients, and decoding messages from them. Messages that can't be decoded
+// are logged and dropped.
+// Clients must offer a supported protocol version as a websocket subprotocol, see protocol.Subprotocol. Clients that
+// don't are closed with the close code protocol.CloseUnsupportedVersion.
+// Clients can choose the codec messages are encoded with, with the query parameter "codec", for instance
+// /zombie?codec=msgpack. See protocol.CodecByName. If omitted, messages are encoded as JSON. Clients asking for an
+// unknown codec are closed with the close code protocol.CloseUnsupportedCodec.
+// Broadcasted world snapshots are sent to each client as deltas from the last snapshot the client acknowledged, see
+// snapshot.Encoder. Acknowledgements from clients are handled here, and not passed on to subscriber.
+// Clients can ask to join a room with the query parameter "room", for instance /zombie?room=abc. The room is passed on
+// to onConnect, which decides what it means.
+// Clients can choose which broadcast topics to receive with the query parameter "topics", for instance
+// /zombie?topics=world,zombies. If omitted, clients receive all topics. The topics are listed in package broadcast: where
+// zombies and players are is on "world", and zombie state changes are on "zombies".
 func New(
 	ctx context.Context,
 	logger *zap.SugaredLogger,
 	allowedCorsOrigins map[string]bool,
 	onConnect connectors.OnConnect,
-	subscriber chan string,
-	broadcaster *broadcast.Broadcaster,
+	onDisconnect connectors.OnDisconnect,
+	subscriber chan connectors.ClientMessage,
+	registry *protocol.Registry,
 ) func(writer http.ResponseWriter, request *http.Request) {
 	upgrader := &websocket.Upgrader{
 		CheckOrigin:       createWebsocketCheckOriginFn(logger, allowedCorsOrigins),
 		EnableCompression: true,
+		Subprotocols:      protocol.Subprotocols(),
 	}
 
+	var lastClientID uint64
+
 	return func(writer http.ResponseWriter, request *http.Request) {
 		connection, err := upgrader.Upgrade(writer, request, nil)
 		if err != nil {
@@ -32,12 +57,43 @@ func New(
 			return
 		}
 
-		logger.Info("Client connected!")
+		if connection.Subprotocol() == "" {
+			logger.Infof("Rejecting client offering unsupported protocol versions %v", websocket.Subprotocols(request))
+			reject(logger, connection, protocol.CloseUnsupportedVersion,
+				fmt.Sprintf("unsupported protocol version, server supports %s", strings.Join(protocol.Subprotocols(), ", ")))
+
+			return
+		}
+
+		codec, err := codecFromRequest(request)
+		if err != nil {
+			logger.Infof("Rejecting client: %s", err.Error())
+			reject(logger, connection, protocol.CloseUnsupportedCodec, err.Error())
+
+			return
+		}
+
+		clientID := strconv.FormatUint(atomic.AddUint64(&lastClientID, 1), 10)
+		logger.Infof("Client %s connected with protocol %s and codec %s!", clientID, connection.Subprotocol(), codec.Name())
+
+		h := NewConnectedHandler(ctx, logger, clientID, connection, subscriber, registry, codec, topicsFromRequest(request))
 
-		h := NewConnectedHandler(ctx, logger, connection, subscriber, broadcaster)
+		defer onDisconnect(clientID)
+
+		messagesToClient, err := onConnect(connectors.Client{ID: clientID, Room: request.URL.Query().Get(roomQueryParameter)}, h.session.Subscribe)
+		if err == nil && h.session.Subscription() == nil {
+			err = errors.New("client wasn't subscribed to any broadcaster")
+		}
+
+		if err != nil {
+			logger.Error("on connect:", err)
+			h.session.Unsubscribe()
+			h.closeAfterForwardingStopped()
+
+			return
+		}
 
 		websocketReadFailureChannel := make(chan bool)
-		messagesToClientChannel := make(chan string)
 
 		go func() {
 			h.log.Info("START readIncomingMessages")
@@ -48,22 +104,59 @@ func New(
 
 		go func() {
 			h.log.Info("START FORWARDING")
-			h.forwardMessagesToClient(messagesToClientChannel)
+			h.forwardMessagesToClient(messagesToClient, websocketReadFailureChannel)
 			h.log.Info("DONE FORWARDING")
 		}()
 
-		err = onConnect(messagesToClientChannel)
-		if err != nil {
-			logger.Error("on connect:", err)
-			return
-		}
-
 		h.log.Info("START h.closeConnectionWhenDone")
 		h.closeConnectionWhenDone(websocketReadFailureChannel)
 		h.log.Info("END h.closeConnectionWhenDone")
 	}
 }
 
+// closeTimeout is how long to wait for a close message to be sent
+const closeTimeout = time.Second
+
+// reject closes a connection from a client the server can't talk with. Closing with a reason lets the client tell its
+// user what's wrong, rather than failing to parse messages.
+func reject(logger *zap.SugaredLogger, connection *websocket.Conn, closeCode int, reason string) {
+	err := connection.WriteControl(
+		websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason), time.Now().Add(closeTimeout))
+	if err != nil {
+		logger.Infof("Could not send close message to rejected client: %s", err.Error())
+	}
+
+	_ = connection.Close()
+}
+
+const (
+	topicsQueryParameter = "topics"
+	codecQueryParameter  = "codec"
+	roomQueryParameter   = "room"
+)
+
+func codecFromRequest(request *http.Request) (protocol.Codec, error) {
+	name := request.URL.Query().Get(codecQueryParameter)
+	if name == "" {
+		return protocol.JSON, nil
+	}
+
+	return protocol.CodecByName(name)
+}
+
+func topicsFromRequest(request *http.Request) []string {
+	topics := make([]string, 0)
+
+	for _, topic := range strings.Split(request.URL.Query().Get(topicsQueryParameter), ",") {
+		topic = strings.TrimSpace(topic)
+		if topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	return topics
+}
+
 type webfn func(r *http.Request) bool
 
 func createWebsocketCheckOriginFn(logger *zap.SugaredLogger, allowedOrigins map[string]bool) webfn {
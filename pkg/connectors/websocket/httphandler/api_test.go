@@ -0,0 +1,654 @@
+package httphandler_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yngvark/gr-zombie/pkg/connectors"
+	"github.com/yngvark/gr-zombie/pkg/connectors/websocket/httphandler"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrigin = "http://localhost:3000"
+
+func TestHandler(t *testing.T) {
+	t.Run("Should keep broadcasting to other clients when one client disconnects", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServer(ctx, broadcaster)
+
+		defer server.Close()
+
+		stayingClient := dial(t, server)
+		defer func() { _ = stayingClient.Close() }()
+
+		leavingClient := dial(t, server)
+
+		// Wait until both clients receive broadcasts, so we know they're subscribed
+		broadcastUntilReceived(t, broadcaster, stayingClient)
+		broadcastUntilReceived(t, broadcaster, leavingClient)
+
+		// When
+		require.NoError(t, leavingClient.Close())
+
+		// Then
+		broadcastDone := make(chan bool)
+
+		go func() {
+			for i := 0; i < 10; i++ {
+				err := broadcaster.BroadCast(message("tick"))
+				assert.NoError(t, err)
+			}
+
+			close(broadcastDone)
+		}()
+
+		ticksReceived := 0
+		for ticksReceived < 10 {
+			if readMessage(t, stayingClient) == "tick" {
+				ticksReceived++
+			}
+		}
+
+		select {
+		case <-broadcastDone:
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "broadcasting blocked after a client disconnected")
+		}
+	})
+}
+
+func TestHandlerOnConnect(t *testing.T) {
+	t.Run("Should send the messages from onConnect first, and then what was broadcast after subscribing", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServerWithClientHandling(ctx,
+			func(_ connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+				if err := broadcaster.BroadCast(message("before subscribing")); err != nil {
+					return nil, err
+				}
+
+				subscribe(broadcaster)
+
+				if err := broadcaster.BroadCast(message("after subscribing")); err != nil {
+					return nil, err
+				}
+
+				return []*protocol.Envelope{message("state 1"), message("state 2")}, nil
+			},
+			func(string) {},
+			make(chan connectors.ClientMessage),
+		)
+
+		defer server.Close()
+
+		// When
+		client := dial(t, server)
+		defer func() { _ = client.Close() }()
+
+		// Then
+		assert.Equal(t, "state 1", readMessage(t, client))
+		assert.Equal(t, "state 2", readMessage(t, client))
+		assert.Equal(t, "after subscribing", readMessage(t, client))
+	})
+}
+
+func TestHandlerRooms(t *testing.T) {
+	t.Run("Should pass the room the client asked for on to onConnect", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		rooms := make(chan string, 1)
+		server := newTestServerWithClientHandling(ctx,
+			func(client connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+				rooms <- client.Room
+				subscribe(broadcast.New(nil))
+
+				return nil, nil
+			},
+			func(string) {},
+			make(chan connectors.ClientMessage),
+		)
+
+		defer server.Close()
+
+		// When
+		client := dialWithQuery(t, server, "?room=abc")
+		defer func() { _ = client.Close() }()
+
+		// Then
+		assert.Equal(t, "abc", <-rooms)
+	})
+
+	t.Run("Should close the connection when onConnect fails", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		server := newTestServerWithClientHandling(ctx,
+			func(connectors.Client, func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+				return nil, errors.New("no such room")
+			},
+			func(string) {},
+			make(chan connectors.ClientMessage),
+		)
+
+		defer server.Close()
+
+		// When
+		client := dialWithQuery(t, server, "?room=abc")
+		defer func() { _ = client.Close() }()
+
+		// Then
+		require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, _, err := client.ReadMessage()
+		require.Error(t, err)
+
+		var netErr net.Error
+		assert.False(t, errors.As(err, &netErr) && netErr.Timeout(), "the connection wasn't closed")
+	})
+}
+
+func TestHandlerSequenceNumbers(t *testing.T) {
+	t.Run("Should number the messages sent to each client", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServer(ctx, broadcaster)
+
+		defer server.Close()
+
+		client := dial(t, server)
+		defer func() { _ = client.Close() }()
+
+		broadcastUntilReceived(t, broadcaster, client)
+
+		// When
+		require.NoError(t, broadcaster.BroadCast(message("1")))
+		require.NoError(t, broadcaster.BroadCast(message("2")))
+
+		// Then
+		previous := readEnvelope(t, client)
+		for *previous.Payload.(*string) == "hello" {
+			previous = readEnvelope(t, client)
+		}
+
+		next := readEnvelope(t, client)
+		assert.Equal(t, "2", *next.Payload.(*string))
+		assert.Equal(t, previous.Seq+1, next.Seq)
+		assert.Equal(t, protocol.Version, next.Version)
+	})
+}
+
+func TestHandlerVersionNegotiation(t *testing.T) {
+	t.Run("Should pick a supported protocol version offered by the client", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		server := newTestServer(ctx, broadcast.New(nil))
+		defer server.Close()
+
+		// When
+		client := dialWithSubprotocols(t, server, "", "gr-zombie.v0", protocol.Subprotocol(protocol.Version))
+		defer func() { _ = client.Close() }()
+
+		// Then
+		assert.Equal(t, protocol.Subprotocol(protocol.Version), client.Subprotocol())
+	})
+
+	testCases := []struct {
+		name         string
+		subprotocols []string
+	}{
+		{name: "no protocol version"},
+		{name: "an unsupported protocol version", subprotocols: []string{"gr-zombie.v0"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run("Should reject clients offering "+tc.name, func(t *testing.T) {
+			// Given
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+
+			connected := make(chan bool, 1)
+			server := newTestServerWithClientHandling(ctx,
+				func(connectors.Client, func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+					connected <- true
+					return nil, nil
+				},
+				func(string) {},
+				make(chan connectors.ClientMessage),
+			)
+
+			defer server.Close()
+
+			// When
+			client := dialWithSubprotocols(t, server, "", tc.subprotocols...)
+			defer func() { _ = client.Close() }()
+
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+			_, _, err := client.ReadMessage()
+
+			// Then
+			closeErr, ok := err.(*websocket.CloseError)
+			require.True(t, ok, "expected close error, got %v", err)
+			assert.Equal(t, protocol.CloseUnsupportedVersion, closeErr.Code)
+			assert.Contains(t, closeErr.Text, protocol.Subprotocol(protocol.Version))
+			assert.Empty(t, connected)
+		})
+	}
+}
+
+func TestHandlerCodecs(t *testing.T) {
+	t.Run("Should send messages as binary frames to clients asking for a binary codec", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServer(ctx, broadcaster)
+
+		defer server.Close()
+
+		client := dialWithQuery(t, server, "?codec=msgpack")
+		defer func() { _ = client.Close() }()
+
+		broadcastUntilReceived(t, broadcaster, client)
+
+		// When
+		require.NoError(t, broadcaster.BroadCast(message("binary")))
+
+		// Then
+		for {
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+			messageType, data, err := client.ReadMessage()
+			require.NoError(t, err)
+			assert.Equal(t, websocket.BinaryMessage, messageType)
+
+			envelope, err := newTestRegistry().Decode(protocol.MessagePack, data)
+			require.NoError(t, err)
+
+			if *envelope.Payload.(*string) == "binary" {
+				break
+			}
+		}
+	})
+
+	t.Run("Should reject clients asking for an unknown codec", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		server := newTestServer(ctx, broadcast.New(nil))
+		defer server.Close()
+
+		// When
+		client := dialWithQuery(t, server, "?codec=xml")
+		defer func() { _ = client.Close() }()
+
+		require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, _, err := client.ReadMessage()
+
+		// Then
+		closeErr, ok := err.(*websocket.CloseError)
+		require.True(t, ok, "expected close error, got %v", err)
+		assert.Equal(t, protocol.CloseUnsupportedCodec, closeErr.Code)
+	})
+}
+
+//nolint:funlen
+func TestHandlerSnapshots(t *testing.T) {
+	t.Run("Should send snapshots as deltas from the last snapshot the client acknowledged", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		subscriber := make(chan connectors.ClientMessage, 1)
+		server := newTestServerWithClientHandling(ctx, subscribeTo(broadcaster), func(string) {}, subscriber)
+
+		defer server.Close()
+
+		client := dial(t, server)
+		defer func() { _ = client.Close() }()
+
+		broadcastUntilReceived(t, broadcaster, client)
+
+		zombieAt := func(x int) []snapshot.Entity {
+			return []snapshot.Entity{{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: "1"}, X: x}}
+		}
+
+		publishSnapshot := func(tick uint64) *snapshot.Delta {
+			e := protocol.NewEnvelope(protocol.TypeWorldDelta, tick, snapshot.New(tick, false, zombieAt(int(tick))))
+			require.NoError(t, broadcaster.BroadCast(e))
+
+			for {
+				received := readEnvelope(t, client)
+				if received.Type == protocol.TypeWorldDelta {
+					return received.Payload.(*snapshot.Delta)
+				}
+			}
+		}
+
+		assert.Equal(t, &snapshot.Delta{Keyframe: true, Entities: zombieAt(1)}, publishSnapshot(1))
+
+		// When
+		ack, err := newTestRegistry().Encode(protocol.JSON, protocol.NewEnvelope(protocol.TypeWorldAck, 0, &snapshot.Ack{Tick: 1}))
+		require.NoError(t, err)
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, ack))
+
+		// Then
+		// The acknowledgement is handled concurrently, so keyframes may be sent until it is
+		var delta *snapshot.Delta
+		for tick := uint64(2); delta == nil || delta.Keyframe; tick++ {
+			delta = publishSnapshot(tick)
+		}
+
+		assert.Equal(t, uint64(1), delta.BaseTick)
+		assert.Empty(t, subscriber, "acknowledgements should not be passed on")
+	})
+}
+
+func TestHandlerTopics(t *testing.T) {
+	t.Run("Should send only messages for the topics in the query parameter", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServer(ctx, broadcaster)
+
+		defer server.Close()
+
+		chatClient := dialWithQuery(t, server, "?topics=chat")
+		defer func() { _ = chatClient.Close() }()
+
+		broadcastUntilReceived(t, broadcaster, chatClient)
+
+		// When
+		require.NoError(t, broadcaster.Publish(broadcast.TopicZombies, message("zombie moved")))
+		require.NoError(t, broadcaster.Publish(broadcast.TopicChat, message("hi")))
+
+		// Then
+		msg := readMessage(t, chatClient)
+		for msg == "hello" {
+			msg = readMessage(t, chatClient)
+		}
+
+		assert.Equal(t, "hi", msg)
+	})
+
+	t.Run("Should send zombie events to clients subscribed to the zombies topic", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServer(ctx, broadcaster)
+
+		defer server.Close()
+
+		zombieClient := dialWithQuery(t, server, "?topics=zombies")
+		defer func() { _ = zombieClient.Close() }()
+
+		broadcastUntilReceived(t, broadcaster, zombieClient)
+
+		// When
+		require.NoError(t, broadcaster.Publish(broadcast.TopicWorld, message("world changed")))
+		require.NoError(t, broadcaster.Publish(broadcast.TopicZombies, message("zombie changed state")))
+
+		// Then
+		msg := readMessage(t, zombieClient)
+		for msg == "hello" {
+			msg = readMessage(t, zombieClient)
+		}
+
+		assert.Equal(t, "zombie changed state", msg)
+	})
+}
+
+func TestHandlerClientMessages(t *testing.T) {
+	t.Run("Should decode messages from clients and tag them with client IDs, and tell when clients disconnect", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		connected := make(chan string, 2)
+		disconnected := make(chan string, 2)
+		subscriber := make(chan connectors.ClientMessage)
+
+		server := newTestServerWithClientHandling(
+			ctx,
+			func(client connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+				connected <- client.ID
+				subscribe(broadcast.New(nil))
+
+				return nil, nil
+			},
+			func(clientID string) { disconnected <- clientID },
+			subscriber,
+		)
+
+		defer server.Close()
+
+		firstClient := dial(t, server)
+		firstID := <-connected
+
+		secondClient := dial(t, server)
+		defer func() { _ = secondClient.Close() }()
+
+		secondID := <-connected
+
+		// When
+		require.NoError(t, secondClient.WriteMessage(websocket.TextMessage, []byte(`{"v":1,"type":"test","payload":"from second"}`)))
+		require.NoError(t, firstClient.WriteMessage(websocket.TextMessage, []byte(`not a message`)))
+		require.NoError(t, firstClient.WriteMessage(websocket.TextMessage, []byte(`{"v":1,"type":"test","payload":"from first"}`)))
+
+		// Then
+		received := map[string]string{}
+		for i := 0; i < 2; i++ {
+			msg := <-subscriber
+			received[msg.ClientID] = *msg.Message.Payload.(*string)
+		}
+
+		assert.NotEqual(t, firstID, secondID)
+		assert.Equal(t, map[string]string{firstID: "from first", secondID: "from second"}, received)
+
+		require.NoError(t, firstClient.Close())
+
+		select {
+		case clientID := <-disconnected:
+			assert.Equal(t, firstID, clientID)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "disconnect was not reported")
+		}
+	})
+}
+
+// TestHandlerConcurrency is most useful when run with the race detector: go test -race
+func TestHandlerConcurrency(t *testing.T) {
+	t.Run("Should handle hundreds of clients connecting and disconnecting while broadcasting", func(t *testing.T) {
+		// Given
+		const clientCount = 200
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broadcaster := broadcast.New(nil)
+		server := newTestServer(ctx, broadcaster)
+
+		defer server.Close()
+
+		go func() {
+			ticker := time.NewTicker(time.Millisecond)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					assert.NoError(t, broadcaster.BroadCast(message("tick")))
+				}
+			}
+		}()
+
+		// When
+		var wg sync.WaitGroup
+
+		for i := 0; i < clientCount; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				client := dial(t, server)
+				defer func() { _ = client.Close() }()
+
+				for received := 0; received < 3; received++ {
+					assert.Equal(t, "tick", readMessage(t, client))
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		// Then
+		assert.Eventually(t, func() bool {
+			return len(broadcaster.Stats()) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func newTestServer(ctx context.Context, broadcaster *broadcast.Broadcaster) *httptest.Server {
+	return newTestServerWithClientHandling(ctx, subscribeTo(broadcaster), func(string) {}, make(chan connectors.ClientMessage))
+}
+
+// subscribeTo returns an OnConnect that subscribes clients to the broadcaster
+func subscribeTo(broadcaster *broadcast.Broadcaster) connectors.OnConnect {
+	return func(_ connectors.Client, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+		subscribe(broadcaster)
+		return nil, nil
+	}
+}
+
+func newTestServerWithClientHandling(
+	ctx context.Context,
+	onConnect connectors.OnConnect,
+	onDisconnect connectors.OnDisconnect,
+	subscriber chan connectors.ClientMessage,
+) *httptest.Server {
+	handler := httphandler.New(
+		ctx,
+		zap.NewNop().Sugar(),
+		map[string]bool{testOrigin: true},
+		onConnect,
+		onDisconnect,
+		subscriber,
+		newTestRegistry(),
+	)
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	return dialWithQuery(t, server, "")
+}
+
+func dialWithQuery(t *testing.T, server *httptest.Server, query string) *websocket.Conn {
+	return dialWithSubprotocols(t, server, query, protocol.Subprotocols()...)
+}
+
+func dialWithSubprotocols(t *testing.T, server *httptest.Server, query string, subprotocols ...string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + query
+	header := http.Header{"Origin": []string{testOrigin}}
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+
+	conn, resp, err := dialer.Dial(url, header)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	return conn
+}
+
+// broadcastUntilReceived broadcasts until the client receives a message. This is necessary because a client is
+// subscribed to the broadcaster asynchronously after connecting.
+func broadcastUntilReceived(t *testing.T, broadcaster *broadcast.Broadcaster, conn *websocket.Conn) {
+	received := make(chan bool)
+
+	go func() {
+		_, _, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		close(received)
+	}()
+
+	for {
+		select {
+		case <-received:
+			return
+		case <-time.After(10 * time.Millisecond):
+			require.NoError(t, broadcaster.BroadCast(message("hello")))
+		}
+	}
+}
+
+// readMessage returns the payload of the next message sent to the client
+func readMessage(t *testing.T, conn *websocket.Conn) string {
+	return *readEnvelope(t, conn).Payload.(*string)
+}
+
+func readEnvelope(t *testing.T, conn *websocket.Conn) *protocol.Envelope {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	envelope, err := newTestRegistry().Decode(protocol.JSON, data)
+	require.NoError(t, err)
+
+	return envelope
+}
+
+// newTestRegistry returns a registry with the message type "test", which has a string payload, and the world snapshot
+// message types
+func newTestRegistry() *protocol.Registry {
+	registry := protocol.NewRegistry()
+	registry.Register("test", func() interface{} { return new(string) })
+	registry.Register(protocol.TypeWorldDelta, func() interface{} { return &snapshot.Delta{} })
+	registry.Register(protocol.TypeWorldAck, func() interface{} { return &snapshot.Ack{} })
+
+	return registry
+}
+
+// message returns a test message with the given text as payload
+func message(text string) *protocol.Envelope {
+	return protocol.NewEnvelope("test", 0, text)
+}
@@ -5,7 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/connectors"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
 	"go.uber.org/zap"
 	"net"
 
@@ -15,11 +16,13 @@ import (
 // ConnectedHandler knows how to handle a specific, connected HTTP websocket connection.
 // It will be used when connection to a client has already been made.
 type ConnectedHandler struct {
-	log         *zap.SugaredLogger
-	ctx         context.Context
-	connection  *websocket.Conn
-	subscriber  chan string
-	broadcaster *broadcast.Broadcaster
+	log        *zap.SugaredLogger
+	ctx        context.Context
+	clientID   string
+	connection *websocket.Conn
+	subscriber chan connectors.ClientMessage
+	codec      protocol.Codec
+	session    *connectors.Session
 }
 
 func (h *ConnectedHandler) readIncomingMessages() {
@@ -42,7 +45,22 @@ func (h *ConnectedHandler) readIncomingMessages() {
 		}
 
 		h.log.Infof("Sending received message to subscriber: %s", message)
-		h.subscriber <- string(message)
+
+		envelope, forGame, err := h.session.Decode(message)
+		if err != nil {
+			h.log.Infof("Ignoring message from client %s: %s", h.clientID, err.Error())
+			continue
+		}
+
+		if !forGame {
+			continue
+		}
+
+		select {
+		case h.subscriber <- connectors.ClientMessage{ClientID: h.clientID, Message: envelope}:
+		case <-h.ctx.Done():
+			return
+		}
 	}
 }
 
@@ -65,39 +83,81 @@ func (h *ConnectedHandler) closeConnectionWhenDone(websocketReadStoppedChannel <
 	}
 }
 
-func (h *ConnectedHandler) forwardMessagesToClient(messagesToClientChannel chan string) {
-	h.broadcaster.AddSubscriber(messagesToClientChannel)
+// forwardMessagesToClient sends the messages from onConnect, and then broadcasted messages, to the client, until the
+// client disconnects or the context is canceled. The subscription is removed on return, so the broadcaster stops
+// queueing messages for a client that is gone.
+func (h *ConnectedHandler) forwardMessagesToClient(
+	messagesFromOnConnect []*protocol.Envelope,
+	websocketReadStoppedChannel <-chan bool,
+) {
+	defer h.session.Unsubscribe()
+
+	for _, msgToClient := range messagesFromOnConnect {
+		err := h.sendMsgToConnection(msgToClient)
+		if err != nil {
+			h.log.Info("Could not send message to client. Stopping handler for this connection.")
+			h.closeAfterForwardingStopped()
+
+			return
+		}
+	}
+
+	subscriber := h.session.Subscription()
 
 	for {
-		select {
-		case msgToClient := <-messagesToClientChannel:
-			err := h.sendMsgToConnection(msgToClient)
-			if err != nil {
-				h.log.Info("Could not send message to client. Stopping handler for this connection.")
+		var msgToClient *protocol.Envelope
 
-				err = h.CloseIt()
-				if err != nil {
-					h.log.Errorf("error closing: %w", err)
-				}
+		select {
+		case msgToClient = <-subscriber.Messages():
+		case <-subscriber.Done():
+			h.log.Infof("Client was too slow to receive messages (%d dropped). Closing connection.", subscriber.Dropped())
+			h.closeAfterForwardingStopped()
 
-				return
-			}
+			return
+		case <-websocketReadStoppedChannel:
+			h.log.Debug("ConnectedHandler.forwardMessagesToClient.websocketReadStoppedChannel. Stopping broadcasting to client.")
+			return
 		case <-h.ctx.Done():
 			h.log.Debug("ConnectedHandler.forwardMessagesToClient.ctx.Done. Stopping broadcasting to client.")
 			return
 		}
+
+		err := h.sendMsgToConnection(msgToClient)
+		if err != nil {
+			h.log.Info("Could not send message to client. Stopping handler for this connection.")
+			h.closeAfterForwardingStopped()
+
+			return
+		}
 	}
 }
 
-// sendMsgToConnection sends a message via the websocket
-func (h *ConnectedHandler) sendMsgToConnection(msg string) error {
+func (h *ConnectedHandler) closeAfterForwardingStopped() {
+	err := h.CloseIt()
+	if err != nil {
+		h.log.Errorf("error closing: %w", err)
+	}
+}
+
+// sendMsgToConnection sends a message via the websocket. Messages encoded with a binary codec are sent as binary frames.
+func (h *ConnectedHandler) sendMsgToConnection(msg *protocol.Envelope) error {
 	if h.connection == nil {
 		return errors.New("could not send message, not connected")
 	}
 
-	//h.log.Debugf("Sending msg: %s", msg)
+	data, err := h.session.Encode(msg)
+	if err != nil {
+		// That's our fault, not the client's, so keep the connection
+		h.log.Errorf("Could not encode message, skipping it: %s", err.Error())
+		return nil
+	}
+
+	messageType := websocket.TextMessage
+	if h.codec.Binary() {
+		messageType = websocket.BinaryMessage
+	}
 
-	err := h.connection.WriteMessage(websocket.TextMessage, []byte(msg))
+	err = h.connection.WriteMessage(messageType, data)
 	if err != nil {
 		return fmt.Errorf("could not write message: %w", err)
 	}
@@ -130,16 +190,21 @@ func (h *ConnectedHandler) CloseIt() error {
 func NewConnectedHandler(
 	ctx context.Context,
 	logger *zap.SugaredLogger,
+	clientID string,
 	connection *websocket.Conn,
-	subscriber chan string,
-	broadcaster *broadcast.Broadcaster,
+	subscriber chan connectors.ClientMessage,
+	registry *protocol.Registry,
+	codec protocol.Codec,
+	topics []string,
 ) *ConnectedHandler {
 	handler := &ConnectedHandler{
-		ctx:         ctx,
-		log:         logger,
-		connection:  connection,
-		subscriber:  subscriber,
-		broadcaster: broadcaster,
+		ctx:        ctx,
+		log:        logger,
+		clientID:   clientID,
+		connection: connection,
+		subscriber: subscriber,
+		codec:      codec,
+		session:    connectors.NewSession(connection.RemoteAddr().String(), topics, registry, codec),
 	}
 
 	return handler
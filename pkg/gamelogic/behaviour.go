@@ -0,0 +1,108 @@
+package gamelogic
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	zombiePkg "github.com/yngvark/gr-zombie/pkg/zombie"
+)
+
+// BehaviourKind names a kind of zombie behaviour
+type BehaviourKind string
+
+const (
+	// BehaviourRandomWalk makes zombies walk randomly
+	BehaviourRandomWalk BehaviourKind = "random"
+	// BehaviourPatrol makes zombies patrol between random waypoints near where they spawned
+	BehaviourPatrol BehaviourKind = "patrol"
+	// BehaviourChase makes zombies chase the nearest player
+	BehaviourChase BehaviourKind = "chase"
+	// BehaviourIdle makes zombies stay put until a player comes close, and then chase the nearest player
+	BehaviourIdle BehaviourKind = "idle"
+	// BehaviourHunt makes zombies idle and wander until they see or hear players, and then hunt them down. Their state
+	// changes are published, so clients can show what they're up to.
+	BehaviourHunt BehaviourKind = "hunt"
+)
+
+const (
+	patrolWaypointCount = 3
+	patrolRadius        = 8
+	idleDisturbRadius   = 5
+	sightRadius         = 8
+	hearingRadius       = 12
+)
+
+// ParseBehaviourKind returns the BehaviourKind with the given name
+func ParseBehaviourKind(name string) (BehaviourKind, error) {
+	kind := BehaviourKind(strings.ToLower(name))
+
+	switch kind {
+	case BehaviourRandomWalk, BehaviourPatrol, BehaviourChase, BehaviourIdle, BehaviourHunt:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("not a valid behaviour: %s", name)
+	}
+}
+
+// BehaviourAssigner returns the Behaviour for the index-th zombie spawned, which is spawned at the given position
+type BehaviourAssigner func(index int, spawn worldmap.Point) zombiePkg.Behaviour
+
+// NewBehaviourAssigner returns a BehaviourAssigner that assigns behaviours of the given kinds to zombies in turn. With
+// no kinds, zombies walk randomly. Chasing zombies go after the positions returned by targets, and hunting zombies can
+// hear the noises returned by noises. The behaviours share a pathfinder, so the zombies must not be moved concurrently.
+func NewBehaviourAssigner(
+	m *worldmap.WorldMap,
+	kinds []BehaviourKind,
+	rnd *rand.Rand,
+	targets zombiePkg.Targets,
+	noises zombiePkg.Noises,
+) (BehaviourAssigner, error) {
+	for _, kind := range kinds {
+		if _, err := ParseBehaviourKind(string(kind)); err != nil {
+			return nil, err
+		}
+	}
+
+	pathfinder := worldmap.NewPathfinder(m, worldmap.EightWay)
+
+	return func(index int, spawn worldmap.Point) zombiePkg.Behaviour {
+		if len(kinds) == 0 {
+			return zombiePkg.RandomWalk{}
+		}
+
+		switch kinds[index%len(kinds)] {
+		case BehaviourPatrol:
+			return zombiePkg.NewPatrol(pathfinder, patrolWaypoints(m, spawn, rnd)...)
+		case BehaviourChase:
+			return zombiePkg.NewChase(pathfinder, targets)
+		case BehaviourIdle:
+			return zombiePkg.NewIdle(
+				zombiePkg.TargetWithin(targets, idleDisturbRadius),
+				zombiePkg.NewChase(pathfinder, targets))
+		case BehaviourHunt:
+			senses := zombiePkg.Senses{SightRadius: sightRadius, HearingRadius: hearingRadius}
+
+			return zombiePkg.NewStateMachine(pathfinder, senses, targets, noises)
+		default:
+			return zombiePkg.RandomWalk{}
+		}
+	}, nil
+}
+
+// patrolWaypoints returns the spawn position followed by random walkable positions near it
+func patrolWaypoints(m *worldmap.WorldMap, spawn worldmap.Point, rnd *rand.Rand) []worldmap.Point {
+	waypoints := []worldmap.Point{spawn}
+
+	for i := 0; i < patrolWaypointCount; i++ {
+		x := spawn.X + rnd.Intn(2*patrolRadius+1) - patrolRadius //nolint:gomnd
+		y := spawn.Y + rnd.Intn(2*patrolRadius+1) - patrolRadius //nolint:gomnd
+
+		if walkableX, walkableY, ok := m.NearestWalkable(x, y); ok {
+			waypoints = append(waypoints, worldmap.Point{X: walkableX, Y: walkableY})
+		}
+	}
+
+	return waypoints
+}
@@ -0,0 +1,49 @@
+package gamelogic
+
+import (
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+)
+
+const (
+	defaultZombieCount  = 1
+	defaultSeed         = 45
+	defaultTickInterval = time.Second
+
+	defaultKeyframeInterval = 30
+)
+
+// Config contains settings for GameLogic
+type Config struct {
+	// ZombieCount is the number of zombies to spawn when the game starts
+	ZombieCount int
+	// Placement decides where the zombies are spawned
+	Placement Placement
+	// Behaviours are assigned to the zombies in turn when they are spawned. If empty, all zombies walk randomly.
+	Behaviours []BehaviourKind
+	// Seed is used for all randomness in the game, so that a game can be reproduced
+	Seed int64
+	// TickInterval is the time between each tick of the game, when running at normal speed
+	TickInterval time.Duration
+	// KeyframeInterval is the number of ticks between each snapshot of the world that is sent whole to every client,
+	// rather than as a delta. If 0, only clients that haven't acknowledged any snapshot are sent whole snapshots.
+	KeyframeInterval uint64
+	// Clock is used for timing the game. If nil, the real wall clock is used.
+	Clock clock.Clock
+	// WorldMap is the map the game is played on. If nil, a default map is generated.
+	WorldMap *worldmap.WorldMap
+}
+
+// DefaultConfig returns the default Config
+func DefaultConfig() Config {
+	return Config{
+		ZombieCount:  defaultZombieCount,
+		Placement:    PlacementCenter,
+		Seed:         defaultSeed,
+		TickInterval: defaultTickInterval,
+
+		KeyframeInterval: defaultKeyframeInterval,
+	}
+}
@@ -0,0 +1,179 @@
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+)
+
+// control contains the state used for controlling the speed of the game. It is safe for concurrent use, so the game
+// can be controlled while running.
+type control struct {
+	mutex        sync.Mutex
+	tickInterval time.Duration
+	speed        float64
+	paused       bool
+	pendingSteps int
+	tick         uint64
+	// ticker is the game loop's ticker, if running
+	ticker clock.Ticker
+
+	// changed is signalled when the state changes, so that the game loop can react to it
+	changed chan struct{}
+}
+
+// MaxSpeed is the highest speed SetSpeed accepts. Running the game faster would only make it use all the CPU it can get.
+const MaxSpeed = 100
+
+// Status describes the current speed and progress of the game
+type Status struct {
+	Paused       bool          `json:"paused"`
+	Speed        float64       `json:"speed"`
+	TickInterval time.Duration `json:"tickInterval"`
+	Tick         uint64        `json:"tick"`
+}
+
+// Pause pauses the game. The game can still be advanced with Step.
+func (l *GameLogic) Pause() {
+	l.control.update(func(c *control) {
+		c.paused = true
+	})
+}
+
+// Resume resumes a paused game
+func (l *GameLogic) Resume() {
+	l.control.update(func(c *control) {
+		c.paused = false
+	})
+}
+
+// Step advances a paused game by one tick. It does nothing if the game isn't paused.
+func (l *GameLogic) Step() {
+	l.control.update(func(c *control) {
+		if c.paused {
+			c.pendingSteps++
+		}
+	})
+}
+
+// SetSpeed sets how fast the game runs, relative to the configured tick interval. For instance, 2 runs the game twice
+// as fast, and 0.5 runs it in slow motion. The speed must be positive and at most MaxSpeed.
+func (l *GameLogic) SetSpeed(speed float64) error {
+	if math.IsNaN(speed) || math.IsInf(speed, 0) || speed <= 0 || speed > MaxSpeed {
+		return fmt.Errorf("speed must be positive and at most %d, was %f", MaxSpeed, speed)
+	}
+
+	l.control.update(func(c *control) {
+		c.speed = speed
+
+		if c.ticker != nil {
+			// Resetting the ticker here rather than in the game loop makes the new speed apply immediately
+			c.ticker.Reset(c.intervalLocked())
+		}
+	})
+
+	return nil
+}
+
+// Status returns the current Status
+func (l *GameLogic) Status() Status {
+	l.control.mutex.Lock()
+	defer l.control.mutex.Unlock()
+
+	return Status{
+		Paused:       l.control.paused,
+		Speed:        l.control.speed,
+		TickInterval: l.control.tickInterval,
+		Tick:         l.control.tick,
+	}
+}
+
+func (c *control) update(fn func(c *control)) {
+	c.mutex.Lock()
+	fn(c)
+	c.mutex.Unlock()
+
+	select {
+	case c.changed <- struct{}{}:
+	default:
+		// The game loop has already been signalled
+	}
+}
+
+// intervalLocked returns the time between ticks, adjusted for speed. The caller must hold the mutex.
+func (c *control) intervalLocked() time.Duration {
+	interval := time.Duration(float64(c.tickInterval) / c.speed)
+	if interval < minInterval {
+		return minInterval
+	}
+
+	return interval
+}
+
+// minInterval prevents very high speeds from resulting in a zero interval, which tickers don't accept
+const minInterval = time.Nanosecond
+
+func (c *control) startTicker(clk clock.Clock) clock.Ticker {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.ticker = clk.NewTicker(c.intervalLocked())
+
+	return c.ticker
+}
+
+func (c *control) stopTicker() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.ticker.Stop()
+	c.ticker = nil
+}
+
+func (c *control) isPaused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.paused
+}
+
+// takeStep returns true and consumes a step if there are any pending steps
+func (c *control) takeStep() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.pendingSteps == 0 {
+		return false
+	}
+
+	c.pendingSteps--
+
+	return true
+}
+
+func (c *control) nextTick() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.tick++
+
+	return c.tick
+}
+
+func (c *control) currentTick() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.tick
+}
+
+func newControl(tickInterval time.Duration) *control {
+	return &control{
+		tickInterval: tickInterval,
+		speed:        1,
+		changed:      make(chan struct{}, 1),
+	}
+}
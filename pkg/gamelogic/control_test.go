@@ -0,0 +1,198 @@
+package gamelogic_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTickInterval = time.Second
+
+//nolint:funlen
+func TestControl(t *testing.T) {
+	t.Run("Should advance a paused game one tick per step", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		game.logic.Pause()
+
+		// When
+		game.logic.Step()
+		<-game.subscriber.Messages()
+
+		game.logic.Step()
+		<-game.subscriber.Messages()
+
+		// Then
+		assert.Equal(t, uint64(2), game.logic.Status().Tick)
+		assert.True(t, game.logic.Status().Paused)
+	})
+
+	t.Run("Should ignore steps when not paused", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		// When
+		game.logic.Step()
+		game.clock.Advance(testTickInterval)
+
+		// Then
+		<-game.subscriber.Messages()
+		assert.Empty(t, game.subscriber.Messages())
+		assert.Equal(t, uint64(1), game.logic.Status().Tick)
+	})
+
+	t.Run("Should not tick when paused", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		// When
+		game.logic.Pause()
+		game.clock.Advance(10 * testTickInterval)
+
+		// Then
+		assert.Empty(t, game.subscriber.Messages())
+		assert.Equal(t, uint64(0), game.logic.Status().Tick)
+	})
+
+	t.Run("Should tick again when resumed", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		game.logic.Pause()
+		game.clock.Advance(testTickInterval)
+
+		// When
+		game.logic.Resume()
+		game.clock.Advance(testTickInterval)
+
+		// Then
+		<-game.subscriber.Messages()
+		assert.Equal(t, uint64(1), game.logic.Status().Tick)
+	})
+
+	t.Run("Should speed up the game", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		// When
+		err := game.logic.SetSpeed(4)
+		require.NoError(t, err)
+
+		game.clock.Advance(testTickInterval)
+
+		// Then
+		for i := 0; i < 4; i++ {
+			<-game.subscriber.Messages()
+		}
+
+		assert.Equal(t, uint64(4), game.logic.Status().Tick)
+		assert.Equal(t, 4.0, game.logic.Status().Speed)
+	})
+
+	t.Run("Should slow down the game", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		// When
+		err := game.logic.SetSpeed(0.5)
+		require.NoError(t, err)
+
+		game.clock.Advance(testTickInterval)
+		assert.Equal(t, uint64(0), game.logic.Status().Tick)
+
+		game.clock.Advance(testTickInterval)
+		<-game.subscriber.Messages()
+
+		// Then
+		assert.Equal(t, uint64(1), game.logic.Status().Tick)
+	})
+
+	t.Run("Should reject non-positive speeds", func(t *testing.T) {
+		game := runGame(t)
+		defer game.cancelFn()
+
+		assert.Error(t, game.logic.SetSpeed(0))
+		assert.Error(t, game.logic.SetSpeed(-1))
+	})
+
+	t.Run("Should reject speeds that aren't finite or are above MaxSpeed", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		// When
+		for _, speed := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e300, gamelogic.MaxSpeed + 1} {
+			assert.Error(t, game.logic.SetSpeed(speed), "speed %f", speed)
+		}
+
+		// Then
+		assert.Equal(t, 1.0, game.logic.Status().Speed)
+
+		_, err := json.Marshal(game.logic.Status())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should accept MaxSpeed", func(t *testing.T) {
+		game := runGame(t)
+		defer game.cancelFn()
+
+		assert.NoError(t, game.logic.SetSpeed(gamelogic.MaxSpeed))
+	})
+}
+
+type testGame struct {
+	logic       *gamelogic.GameLogic
+	clock       *clock.Fake
+	subscriber  *broadcast.Subscriber
+	broadcaster *broadcast.Broadcaster
+	cancelFn    context.CancelFunc
+}
+
+// runGame runs a game with a fake clock, and waits until the game is ready to tick
+func runGame(t *testing.T) testGame {
+	return runGameWithConfig(t, gamelogic.DefaultConfig())
+}
+
+// runGameWithConfig is like runGame, with the given config, except for the tick interval and clock
+func runGameWithConfig(t *testing.T, config gamelogic.Config) testGame {
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	broadcaster := broadcast.New(nil)
+	subscriber := broadcaster.AddSubscriber("test")
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+
+	config.TickInterval = testTickInterval
+	config.Clock = fakeClock
+
+	gameLogic, err := gamelogic.NewGameLogic(ctx, zap.NewNop().Sugar(), broadcaster, config)
+	require.NoError(t, err)
+
+	go gameLogic.Run()
+
+	fakeClock.WaitForTickers(1)
+
+	return testGame{
+		logic:       gameLogic,
+		clock:       fakeClock,
+		subscriber:  subscriber,
+		broadcaster: broadcaster,
+		cancelFn:    cancelFn,
+	}
+}
@@ -2,12 +2,17 @@ package gamelogic
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
 	"math/rand"
-	"time"
+	"sync"
 
-	zombie2 "github.com/yngvark/gr-zombie/pkg/zombie"
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+
+	"github.com/yngvark/gr-zombie/pkg/zombie"
 	"go.uber.org/zap"
 
 	"github.com/yngvark/gr-zombie/pkg/worldmap"
@@ -15,18 +20,35 @@ import (
 
 // GameLogic knows how to run the game
 type GameLogic struct {
+	// mutex is held while the world changes and is published, so that joining clients can be brought up to date
+	// without missing or repeating anything
+	mutex       sync.Mutex
 	log         *zap.SugaredLogger
 	broadcaster *broadcast.Broadcaster
 	ctx         context.Context
 	generator   *Generator
+	control     *control
+	clock       clock.Clock
+	worldMap    *worldmap.WorldMap
+	players     *player.Players
+	// keyframeInterval is the number of ticks between each snapshot that is sent whole to every client
+	keyframeInterval uint64
+	// playerNoises are the positions of the noises players made during the current tick
+	playerNoises []worldmap.Point
+}
+
+// WorldMap returns the map the game is played on. This is the authoritative map, which should be sent to clients so
+// that what they draw matches what the game simulates.
+func (l *GameLogic) WorldMap() *worldmap.WorldMap {
+	return l.worldMap
 }
 
 // Run continuously publishes messages with game logic events. It blocks until signalled to stop.
 func (l *GameLogic) Run() {
 	l.log.Info("Producing game events...")
 
-	ticker := time.NewTicker(time.Second * 1) //nolint:gomnd
-	defer ticker.Stop()
+	ticker := l.control.startTicker(l.clock)
+	defer l.control.stopTicker()
 
 	for {
 		select {
@@ -34,37 +56,165 @@ func (l *GameLogic) Run() {
 			l.log.Debug("GameLogic.ctx.Done")
 
 			return
-		case <-ticker.C:
-			zombieMove, err := l.generator.Next()
-			if err != nil {
-				l.log.Info("could not generate next message: %w", err)
-				return
+		case <-l.control.changed:
+			for l.control.takeStep() {
+				err := l.tick()
+				if err != nil {
+					l.log.Error(err)
+					return
+				}
 			}
-
-			zombieMoveJSON, err := json.Marshal(zombieMove)
-			if err != nil {
-				l.log.Info("could not marshal zombie move: %w", err)
-				return
+		case <-ticker.C():
+			if l.control.isPaused() {
+				continue
 			}
 
-			err = l.broadcaster.BroadCast(string(zombieMoveJSON))
+			err := l.tick()
 			if err != nil {
-				l.log.Error("-- WE SHOULD NEVER SEE THIS I THINK, PUBLISHER FAILED AND SHOULD CANCEL THE CONTEXT")
+				l.log.Error(err)
 				return
 			}
 		}
 	}
 }
 
+// tick advances the game one tick, and publishes a snapshot of the resulting state of the world. The snapshot is
+// published as a worldDelta message, and it's up to the connectors to turn it into a delta for each client. Zombies
+// that changed state are also published as a zombieStates message, so that clients can react to the change itself, for
+// instance by playing an animation.
+func (l *GameLogic) tick() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	tick := l.control.nextTick()
+
+	l.movePlayers()
+
+	// Zombies that are killed don't get to move first
+	stateChanges := l.attackZombies()
+
+	moveStateChanges, err := l.generator.Next()
+	if err != nil {
+		return fmt.Errorf("could not generate next message: %w", err)
+	}
+
+	stateChanges = append(stateChanges, moveStateChanges...)
+
+	if len(stateChanges) > 0 {
+		err = l.publish(broadcast.TopicZombies, protocol.TypeZombieStates, zombie.NewZombieStateChanges(stateChanges))
+		if err != nil {
+			return fmt.Errorf("publishing zombie state changes: %w", err)
+		}
+	}
+
+	keyframe := l.keyframeInterval > 0 && tick%l.keyframeInterval == 0
+
+	err = l.publish(broadcast.TopicWorld, protocol.TypeWorldDelta, l.snapshot(tick, keyframe))
+	if err != nil {
+		return fmt.Errorf("publishing snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// snapshot returns a snapshot of where the zombies and players are
+func (l *GameLogic) snapshot(tick uint64, keyframe bool) *snapshot.Snapshot {
+	zombies := l.generator.Zombies()
+	players := l.players.All()
+	entities := make([]snapshot.Entity, 0, len(zombies)+len(players))
+
+	for _, z := range zombies {
+		state, _ := z.State()
+		entities = append(entities, snapshot.Entity{
+			Key:   snapshot.Key{Kind: snapshot.KindZombie, ID: z.ID},
+			X:     z.X,
+			Y:     z.Y,
+			State: string(state),
+		})
+	}
+
+	for _, p := range players {
+		entities = append(entities, snapshot.Entity{
+			Key: snapshot.Key{Kind: snapshot.KindPlayer, ID: p.ID},
+			X:   p.X,
+			Y:   p.Y,
+		})
+	}
+
+	return snapshot.New(tick, keyframe, entities)
+}
+
+// publish publishes a message to the given topic
+func (l *GameLogic) publish(topic string, msgType string, payload interface{}) error {
+	return l.broadcaster.Publish(topic, l.NewEnvelope(msgType, payload))
+}
+
+// NewEnvelope returns an Envelope with a message created at the game's current tick
+func (l *GameLogic) NewEnvelope(msgType string, payload interface{}) *protocol.Envelope {
+	return protocol.NewEnvelope(msgType, l.control.currentTick(), payload)
+}
+
+// targets returns the positions zombies are after, which are the players' positions
+func (l *GameLogic) targets() []worldmap.Point {
+	return l.players.Positions()
+}
+
+// noises returns the positions where noises were made during the current tick
+func (l *GameLogic) noises() []worldmap.Point {
+	return l.playerNoises
+}
+
+// Zombies returns the zombies in the game. The zombies are moved by Run, so don't use them while the game is running.
+func (l *GameLogic) Zombies() []*zombie.Zombie {
+	return l.generator.Zombies()
+}
+
 // NewGameLogic returns a new GameLogic
-func NewGameLogic(ctx context.Context, logger *zap.SugaredLogger, broadcaster *broadcast.Broadcaster) *GameLogic {
-	m := worldmap.New(20, 10)                                                //nolint:gomnd
-	zombie := zombie2.NewZombie("1", 10, 5, m, rand.New(rand.NewSource(45))) //nolint:gosec,gomnd
+func NewGameLogic(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	broadcaster *broadcast.Broadcaster,
+	config Config,
+) (*GameLogic, error) {
+	if config.TickInterval <= 0 {
+		return nil, fmt.Errorf("tick interval must be positive, was %s", config.TickInterval)
+	}
+
+	gameClock := config.Clock
+	if gameClock == nil {
+		gameClock = clock.New()
+	}
 
-	return &GameLogic{
+	m := config.WorldMap
+	if m == nil {
+		m = worldmap.New(20, 10) //nolint:gomnd
+	}
+
+	l := &GameLogic{
 		log:         logger,
 		broadcaster: broadcaster,
 		ctx:         ctx,
-		generator:   NewGenerator(zombie),
+		control:     newControl(config.TickInterval),
+		clock:       gameClock,
+		worldMap:    m,
+		players:     player.NewPlayers(m),
+
+		keyframeInterval: config.KeyframeInterval,
 	}
+
+	rnd := rand.New(rand.NewSource(config.Seed)) //nolint:gosec
+
+	assign, err := NewBehaviourAssigner(m, config.Behaviours, rnd, l.targets, l.noises)
+	if err != nil {
+		return nil, fmt.Errorf("creating zombie behaviours: %w", err)
+	}
+
+	zombies, err := SpawnZombiesWithBehaviour(m, config.ZombieCount, config.Placement, rnd, assign)
+	if err != nil {
+		return nil, fmt.Errorf("spawning zombies: %w", err)
+	}
+
+	l.generator = NewGenerator(zombies...)
+
+	return l, nil
 }
@@ -0,0 +1,131 @@
+package gamelogic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameLogic(t *testing.T) {
+	t.Run("Should broadcast a snapshot of the world once per tick", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		expectedPositions := [][2]int{{9, 5}, {8, 4}, {9, 4}, {8, 5}, {8, 5}}
+
+		for i, position := range expectedPositions {
+			// When
+			game.clock.Advance(testTickInterval)
+
+			// Then
+			e := <-game.subscriber.Messages()
+			assert.Equal(t, protocol.TypeWorldDelta, e.Type)
+			assert.Equal(t, uint64(i+1), e.Tick)
+
+			s := e.Payload.(*snapshot.Snapshot)
+			assert.Equal(t, e.Tick, s.Tick)
+			assert.Equal(t, []snapshot.Entity{zombieEntity("1", position[0], position[1])}, s.Entities())
+		}
+
+		assert.Empty(t, game.subscriber.Messages())
+	})
+
+	t.Run("Should make every snapshot at the keyframe interval a keyframe", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		interval := gamelogic.DefaultConfig().KeyframeInterval
+
+		for tick := uint64(1); tick <= 2*interval; tick++ {
+			// When
+			game.clock.Advance(testTickInterval)
+
+			// Then
+			s := (<-game.subscriber.Messages()).Payload.(*snapshot.Snapshot)
+			assert.Equal(t, tick%interval == 0, s.Keyframe, "tick %d", tick)
+		}
+	})
+
+	t.Run("Should broadcast zombie state changes on the zombies topic", func(t *testing.T) {
+		// Given
+		config := gamelogic.DefaultConfig()
+		config.Behaviours = []gamelogic.BehaviourKind{gamelogic.BehaviourHunt}
+
+		game := runGameWithConfig(t, config)
+		defer game.cancelFn()
+
+		worldOnly := game.broadcaster.AddSubscriber("world", broadcast.TopicWorld)
+
+		// When
+		// Hunting zombies start out idle, and start wandering now and then when nothing is around
+		var stateChanges *zombie.StateChanges
+
+		for tick := 0; tick < 100 && stateChanges == nil; tick++ {
+			game.clock.Advance(testTickInterval)
+
+			// State changes are published before the snapshot of the tick
+			for e := <-game.subscriber.Messages(); e.Type != protocol.TypeWorldDelta; e = <-game.subscriber.Messages() {
+				assert.Equal(t, protocol.TypeZombieStates, e.Type)
+
+				stateChanges = e.Payload.(*zombie.StateChanges)
+			}
+		}
+
+		// Then
+		require.NotNil(t, stateChanges)
+		assert.Equal(t, []*zombie.StateChange{zombie.NewZombieStateChange("1", zombie.StateWandering)}, stateChanges.States)
+
+		for len(worldOnly.Messages()) > 0 {
+			assert.Equal(t, protocol.TypeWorldDelta, (<-worldOnly.Messages()).Type)
+		}
+	})
+
+	t.Run("Should not broadcast before the first tick", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		// When
+		game.clock.Advance(testTickInterval - 1)
+
+		// Then
+		assert.Empty(t, game.subscriber.Messages())
+	})
+}
+
+func zombieEntity(id string, x int, y int) snapshot.Entity {
+	return snapshot.Entity{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: id}, X: x, Y: y}
+}
+
+func TestWorldMap(t *testing.T) {
+	t.Run("Should play on the configured map", func(t *testing.T) {
+		// Given
+		m := worldmap.New(3, 3)
+
+		config := gamelogic.DefaultConfig()
+		config.WorldMap = m
+
+		// When
+		gameLogic, err := gamelogic.NewGameLogic(context.Background(), zap.NewNop().Sugar(), broadcast.New(nil), config)
+		require.NoError(t, err)
+
+		// Then
+		assert.Same(t, m, gameLogic.WorldMap())
+
+		for _, z := range gameLogic.Zombies() {
+			assert.Same(t, m, z.WorldMap)
+		}
+	})
+}
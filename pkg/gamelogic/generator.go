@@ -7,26 +7,41 @@ import (
 	zombiePkg "github.com/yngvark/gr-zombie/pkg/zombie"
 )
 
-// Generator knows how to generate zombie actions
+// Generator knows how to generate zombie actions for a population of zombies
 type Generator struct {
-	zombie *zombiePkg.Zombie
+	zombies []*zombiePkg.Zombie
 }
 
-// Next returns the next zombie action
-func (g *Generator) Next() (*zombiePkg.Move, error) {
-	z, move, err := g.zombie.Move()
-	if err != nil {
-		return nil, fmt.Errorf("could not move zombie: %w", err)
+// Next moves every zombie, and returns the state changes of the zombies whose behaviour keeps track of their state
+func (g *Generator) Next() ([]*zombiePkg.StateChange, error) {
+	var stateChanges []*zombiePkg.StateChange
+
+	for i, zombie := range g.zombies {
+		stateBefore, _ := zombie.State()
+
+		z, _, err := zombie.Move()
+		if err != nil {
+			return nil, fmt.Errorf("could not move zombie %s: %w", zombie.ID, err)
+		}
+
+		if state, ok := z.State(); ok && state != stateBefore {
+			stateChanges = append(stateChanges, zombiePkg.NewZombieStateChange(z.ID, state))
+		}
+
+		g.zombies[i] = z
 	}
 
-	g.zombie = z
+	return stateChanges, nil
+}
 
-	return move, nil
+// Zombies returns the zombies managed by the Generator
+func (g *Generator) Zombies() []*zombiePkg.Zombie {
+	return g.zombies
 }
 
 // NewGenerator returns a new Generator
-func NewGenerator(initialZombie *zombiePkg.Zombie) *Generator {
+func NewGenerator(initialZombies ...*zombiePkg.Zombie) *Generator {
 	return &Generator{
-		zombie: initialZombie,
+		zombies: initialZombies,
 	}
 }
@@ -0,0 +1,196 @@
+package gamelogic_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	zombiePkg "github.com/yngvark/gr-zombie/pkg/zombie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator(t *testing.T) {
+	t.Run("Should move every zombie at most one step per tick", func(t *testing.T) {
+		// Given
+		m := worldmap.New(100, 100)                                                                             //nolint:gomnd
+		zombies, err := gamelogic.SpawnZombies(m, 500, gamelogic.PlacementRandom, rand.New(rand.NewSource(45))) //nolint:gosec,gomnd
+		require.NoError(t, err)
+
+		before := make([]worldmap.Point, 0, len(zombies))
+		for _, z := range zombies {
+			before = append(before, z.Position())
+		}
+
+		generator := gamelogic.NewGenerator(zombies...)
+
+		// When
+		_, err = generator.Next()
+		require.NoError(t, err)
+
+		// Then
+		require.Len(t, generator.Zombies(), 500)
+
+		moved := 0
+
+		for i, z := range generator.Zombies() {
+			assert.Equal(t, zombies[i].ID, z.ID)
+			assert.LessOrEqual(t, abs(z.X-before[i].X), 1)
+			assert.LessOrEqual(t, abs(z.Y-before[i].Y), 1)
+
+			if z.Position() != before[i] {
+				moved++
+			}
+		}
+
+		assert.Greater(t, moved, 0)
+	})
+}
+
+func TestGeneratorStateChanges(t *testing.T) {
+	t.Run("Should emit state changes only for zombies that changed state", func(t *testing.T) {
+		// Given
+		m := worldmap.New(20, 10) //nolint:gomnd
+		targets := func() []worldmap.Point { return []worldmap.Point{{X: 12, Y: 5}} }
+		senses := zombiePkg.Senses{SightRadius: 5, HearingRadius: 5}
+		hunter := zombiePkg.NewStateMachine(worldmap.NewPathfinder(m, worldmap.EightWay), senses, targets, nil)
+
+		generator := gamelogic.NewGenerator(
+			zombiePkg.NewZombieWithBehaviour("1", 9, 5, m, rand.New(rand.NewSource(45)), hunter), //nolint:gosec,gomnd
+			zombiePkg.NewZombie("2", 1, 1, m, rand.New(rand.NewSource(45))),                      //nolint:gosec
+		)
+
+		// When+Then
+		stateChanges, err := generator.Next()
+		require.NoError(t, err)
+		assert.Equal(t, []*zombiePkg.StateChange{zombiePkg.NewZombieStateChange("1", zombiePkg.StateChasing)}, stateChanges)
+
+		stateChanges, err = generator.Next()
+		require.NoError(t, err)
+		assert.Empty(t, stateChanges)
+
+		stateChanges, err = generator.Next()
+		require.NoError(t, err)
+		assert.Equal(t, []*zombiePkg.StateChange{zombiePkg.NewZombieStateChange("1", zombiePkg.StateAttacking)}, stateChanges)
+	})
+}
+
+func TestSpawnZombies(t *testing.T) {
+	m := worldmap.New(20, 10) //nolint:gomnd
+
+	t.Run("Should place all zombies in the center", func(t *testing.T) {
+		zombies, err := gamelogic.SpawnZombies(m, 3, gamelogic.PlacementCenter, rand.New(rand.NewSource(45))) //nolint:gosec
+		require.NoError(t, err)
+
+		require.Len(t, zombies, 3)
+
+		for _, z := range zombies {
+			assert.Equal(t, 10, z.X)
+			assert.Equal(t, 5, z.Y)
+		}
+	})
+
+	t.Run("Should spread zombies evenly in a grid", func(t *testing.T) {
+		zombies, err := gamelogic.SpawnZombies(m, 4, gamelogic.PlacementGrid, rand.New(rand.NewSource(45))) //nolint:gosec
+		require.NoError(t, err)
+
+		positions := make([][2]int, 0)
+		for _, z := range zombies {
+			positions = append(positions, [2]int{z.X, z.Y})
+		}
+
+		assert.Equal(t, [][2]int{{5, 2}, {15, 2}, {5, 7}, {15, 7}}, positions)
+	})
+
+	t.Run("Should place zombies randomly within the map, the same way for the same seed", func(t *testing.T) {
+		zombies, err := gamelogic.SpawnZombies(m, 50, gamelogic.PlacementRandom, rand.New(rand.NewSource(45))) //nolint:gosec
+		require.NoError(t, err)
+
+		sameSeedZombies, err := gamelogic.SpawnZombies(m, 50, gamelogic.PlacementRandom, rand.New(rand.NewSource(45))) //nolint:gosec
+		require.NoError(t, err)
+
+		for i, z := range zombies {
+			assert.True(t, z.X >= m.MinX && z.X < m.MaxX)
+			assert.True(t, z.Y >= m.MinY && z.Y < m.MaxY)
+			assert.Equal(t, sameSeedZombies[i].X, z.X)
+			assert.Equal(t, sameSeedZombies[i].Y, z.Y)
+		}
+	})
+
+	t.Run("Should give zombies unique IDs", func(t *testing.T) {
+		zombies, err := gamelogic.SpawnZombies(m, 10, gamelogic.PlacementCenter, rand.New(rand.NewSource(45))) //nolint:gosec
+		require.NoError(t, err)
+
+		ids := make(map[string]bool)
+		for _, z := range zombies {
+			ids[z.ID] = true
+		}
+
+		assert.Len(t, ids, 10)
+	})
+
+	t.Run("Should fail on unknown placement", func(t *testing.T) {
+		_, err := gamelogic.SpawnZombies(m, 1, gamelogic.Placement("everywhere"), rand.New(rand.NewSource(45))) //nolint:gosec
+		assert.Error(t, err)
+	})
+
+	t.Run("Should fail on a negative count", func(t *testing.T) {
+		_, err := gamelogic.SpawnZombies(m, -1, gamelogic.PlacementRandom, rand.New(rand.NewSource(45))) //nolint:gosec
+		assert.Error(t, err)
+	})
+}
+
+func TestBehaviourAssigner(t *testing.T) {
+	m := worldmap.New(20, 10) //nolint:gomnd
+
+	t.Run("Should assign behaviours to zombies in turn", func(t *testing.T) {
+		// Given
+		assign, err := gamelogic.NewBehaviourAssigner(m,
+			[]gamelogic.BehaviourKind{gamelogic.BehaviourRandomWalk, gamelogic.BehaviourChase},
+			rand.New(rand.NewSource(45)), //nolint:gosec
+			func() []worldmap.Point { return nil }, nil)
+		require.NoError(t, err)
+
+		// When
+		zombies, err := gamelogic.SpawnZombiesWithBehaviour(m, 3, gamelogic.PlacementCenter,
+			rand.New(rand.NewSource(45)), assign) //nolint:gosec
+		require.NoError(t, err)
+
+		// Then
+		assert.IsType(t, zombiePkg.RandomWalk{}, zombies[0].Behaviour)
+		assert.IsType(t, &zombiePkg.Chase{}, zombies[1].Behaviour)
+		assert.IsType(t, zombiePkg.RandomWalk{}, zombies[2].Behaviour)
+	})
+
+	t.Run("Should give each zombie its own patrol", func(t *testing.T) {
+		// Given
+		assign, err := gamelogic.NewBehaviourAssigner(m, []gamelogic.BehaviourKind{gamelogic.BehaviourPatrol},
+			rand.New(rand.NewSource(45)), nil, nil) //nolint:gosec
+		require.NoError(t, err)
+
+		// When
+		zombies, err := gamelogic.SpawnZombiesWithBehaviour(m, 2, gamelogic.PlacementCenter,
+			rand.New(rand.NewSource(45)), assign) //nolint:gosec
+		require.NoError(t, err)
+
+		// Then
+		assert.IsType(t, &zombiePkg.Patrol{}, zombies[0].Behaviour)
+		assert.NotSame(t, zombies[0].Behaviour, zombies[1].Behaviour)
+	})
+
+	t.Run("Should fail on unknown behaviours", func(t *testing.T) {
+		_, err := gamelogic.NewBehaviourAssigner(m, []gamelogic.BehaviourKind{"dance"},
+			rand.New(rand.NewSource(45)), nil, nil) //nolint:gosec
+		assert.Error(t, err)
+	})
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
@@ -0,0 +1,52 @@
+package gamelogic
+
+import (
+	"fmt"
+
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+)
+
+// NewRegistry returns a protocol.Registry with the message types of the game
+func NewRegistry() *protocol.Registry {
+	r := protocol.NewRegistry()
+
+	r.Register(protocol.TypeMapCreate, func() interface{} { return &worldmap.WorldMap{} })
+	r.Register(protocol.TypeWorldDelta, func() interface{} { return &snapshot.Delta{} })
+	r.Register(protocol.TypeZombieStates, func() interface{} { return &zombie.StateChanges{} })
+	r.Register(protocol.TypePlayerWelcome, func() interface{} { return &player.WelcomeMessage{} })
+	r.Register(protocol.TypePlayerPositions, func() interface{} { return &player.PositionsMessage{} })
+	r.Register(protocol.TypePlayerLeft, func() interface{} { return &player.LeftMessage{} })
+	r.Register(protocol.TypeLobbyState, func() interface{} { return &lobby.StateMessage{} })
+	r.Register(protocol.TypePlayerMove, func() interface{} { return &player.MoveCommand{} })
+	r.Register(protocol.TypePlayerAttack, func() interface{} { return &player.AttackCommand{} })
+	r.Register(protocol.TypeWorldAck, func() interface{} { return &snapshot.Ack{} })
+	r.Register(protocol.TypeLobbyReady, func() interface{} { return &lobby.ReadyCommand{} })
+
+	return r
+}
+
+// RegisterHandlers makes the dispatcher pass messages from clients on to the game
+func (l *GameLogic) RegisterHandlers(d *protocol.Dispatcher) {
+	d.Handle(protocol.TypePlayerMove, func(clientID string, payload interface{}) error {
+		command, ok := payload.(*player.MoveCommand)
+		if !ok {
+			return fmt.Errorf("unexpected payload type %T", payload)
+		}
+
+		return l.MovePlayer(clientID, *command)
+	})
+
+	d.Handle(protocol.TypePlayerAttack, func(clientID string, payload interface{}) error {
+		command, ok := payload.(*player.AttackCommand)
+		if !ok {
+			return fmt.Errorf("unexpected payload type %T", payload)
+		}
+
+		return l.Attack(clientID, *command)
+	})
+}
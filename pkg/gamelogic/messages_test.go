@@ -0,0 +1,113 @@
+package gamelogic_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var codecs = []protocol.Codec{protocol.JSON, protocol.MessagePack, protocol.Protobuf} //nolint:gochecknoglobals
+
+func TestRegistry(t *testing.T) {
+	caves, err := worldmap.Generate("caves", 64, 48, 1)
+	require.NoError(t, err)
+
+	positions := []*player.PositionMessage{{ID: "a", X: -3, Y: 7}, {ID: "b"}}
+
+	payloads := map[string]interface{}{
+		protocol.TypeMapCreate: caves,
+		protocol.TypeWorldDelta: &snapshot.Delta{
+			BaseTick: 40,
+			Entities: []snapshot.Entity{
+				{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: "1"}, X: -1, Y: 200, State: string(zombie.StateChasing)},
+				{Key: snapshot.Key{Kind: snapshot.KindPlayer, ID: "1"}},
+			},
+			Removed: []snapshot.Key{{Kind: snapshot.KindZombie, ID: "2"}},
+		},
+		protocol.TypeZombieStates: zombie.NewZombieStateChanges([]*zombie.StateChange{
+			zombie.NewZombieStateChange("1", zombie.StateChasing),
+			zombie.NewZombieStateChange("2", zombie.StateIdle),
+		}),
+		protocol.TypePlayerWelcome:   player.NewWelcomeMessage("a", positions),
+		protocol.TypePlayerPositions: player.NewPositionsMessage(positions),
+		protocol.TypePlayerLeft:      player.NewLeftMessage("a"),
+		protocol.TypePlayerMove:      &player.MoveCommand{DX: -1, DY: 1},
+		protocol.TypePlayerAttack:    &player.AttackCommand{DX: 1, DY: -1},
+		protocol.TypeWorldAck:        &snapshot.Ack{Tick: 41},
+		protocol.TypeLobbyState: &lobby.StateMessage{
+			CountdownMillis: 1500,
+			Size:            4,
+			Players:         []*lobby.PlayerState{{ID: "a", Ready: true}, {ID: "b"}},
+		},
+		protocol.TypeLobbyReady: &lobby.ReadyCommand{Ready: true},
+	}
+
+	registry := gamelogic.NewRegistry()
+
+	for _, codec := range codecs {
+		for msgType, payload := range payloads {
+			codec, msgType, payload := codec, msgType, payload
+
+			t.Run(fmt.Sprintf("Should encode and decode %s messages as %s", msgType, codec.Name()), func(t *testing.T) {
+				// Given
+				e := protocol.NewEnvelope(msgType, 42, payload).WithSeq(3)
+
+				// When
+				data, err := registry.Encode(codec, e)
+				require.NoError(t, err)
+
+				decoded, err := registry.Decode(codec, data)
+				require.NoError(t, err)
+
+				// Then
+				assert.Equal(t, e, decoded)
+			})
+		}
+	}
+}
+
+// BenchmarkEncodeZombieMoves compares the codecs by encoding a tick where 1000 zombies move. Clients are sent the
+// whole world like this in keyframes, and otherwise as deltas, which are smaller.
+func BenchmarkEncodeZombieMoves(b *testing.B) {
+	const zombieCount = 1000
+
+	entities := make([]snapshot.Entity, zombieCount)
+	for i := range entities {
+		entities[i] = snapshot.Entity{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: strconv.Itoa(i + 1)}, X: i % 256, Y: i / 256}
+	}
+
+	e := protocol.NewEnvelope(protocol.TypeWorldDelta, 1000, snapshot.New(1000, true, entities).Full()).WithSeq(1000)
+	registry := gamelogic.NewRegistry()
+
+	for _, codec := range codecs {
+		codec := codec
+
+		b.Run(codec.Name(), func(b *testing.B) {
+			var data []byte
+
+			var err error
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				data, err = registry.Encode(codec, e)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(len(data)), "bytes/tick")
+		})
+	}
+}
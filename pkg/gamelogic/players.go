@@ -0,0 +1,102 @@
+package gamelogic
+
+import (
+	"fmt"
+
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+)
+
+// AddPlayer adds a player to the game, and tells everyone where it is. It returns the messages that bring the player's
+// client up to date, which are the world map, the message welcoming the player and a snapshot of the world.
+// subscribe must make the client start receiving broadcasts. It is called while the world stands still, so the client
+// receives every broadcast after the returned messages, and nothing they already cover.
+func (l *GameLogic) AddPlayer(id string, subscribe func()) ([]*protocol.Envelope, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	p, err := l.players.Join(id)
+	if err != nil {
+		return nil, fmt.Errorf("joining: %w", err)
+	}
+
+	// Published before subscribing, as the welcome message already tells the client where its player is
+	err = l.publish(broadcast.TopicPlayers, protocol.TypePlayerPositions, player.NewPositionsMessage(
+		[]*player.PositionMessage{player.NewPositionMessage(p)}))
+	if err != nil {
+		return nil, fmt.Errorf("publishing player position: %w", err)
+	}
+
+	subscribe()
+
+	return []*protocol.Envelope{
+		l.NewEnvelope(protocol.TypeMapCreate, l.worldMap),
+		l.NewEnvelope(protocol.TypePlayerWelcome, player.NewWelcomeMessage(id, positionMessages(l.players.All()))),
+		l.NewEnvelope(protocol.TypeWorldDelta, l.snapshot(l.control.currentTick(), true)),
+	}, nil
+}
+
+// RemovePlayer removes a player from the game, and tells everyone it left
+func (l *GameLogic) RemovePlayer(id string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.players.Leave(id) {
+		return nil
+	}
+
+	err := l.publish(broadcast.TopicPlayers, protocol.TypePlayerLeft, player.NewLeftMessage(id))
+	if err != nil {
+		return fmt.Errorf("publishing player left: %w", err)
+	}
+
+	return nil
+}
+
+// MovePlayer queues a command to move a player, to be applied on the next tick
+func (l *GameLogic) MovePlayer(id string, command player.MoveCommand) error {
+	return l.players.Command(id, command)
+}
+
+// Attack queues a command to make a player attack, to be applied on the next tick
+func (l *GameLogic) Attack(id string, command player.AttackCommand) error {
+	return l.players.Attack(id, command)
+}
+
+// attackZombies applies the players' attacks, killing the zombies on the attacked tiles. It returns the state changes
+// of the zombies that died.
+func (l *GameLogic) attackZombies() []*zombie.StateChange {
+	var killed []*zombie.StateChange
+
+	for _, position := range l.players.ApplyAttacks() {
+		for _, z := range l.generator.Zombies() {
+			if z.Position() == position && z.Kill() {
+				killed = append(killed, zombie.NewZombieStateChange(z.ID, zombie.StateDead))
+			}
+		}
+	}
+
+	return killed
+}
+
+// movePlayers applies the players' commands. The players make noise when moving, which zombies may hear.
+func (l *GameLogic) movePlayers() {
+	moved := l.players.ApplyCommands()
+
+	l.playerNoises = make([]worldmap.Point, 0, len(moved))
+	for _, p := range moved {
+		l.playerNoises = append(l.playerNoises, p.Position())
+	}
+}
+
+func positionMessages(players []*player.Player) []*player.PositionMessage {
+	messages := make([]*player.PositionMessage, 0, len(players))
+	for _, p := range players {
+		messages = append(messages, player.NewPositionMessage(p))
+	}
+
+	return messages
+}
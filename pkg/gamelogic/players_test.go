@@ -0,0 +1,176 @@
+package gamelogic_test
+
+import (
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestPlayers(t *testing.T) {
+	t.Run("Should bring new players up to date, and tell everyone where they are", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		_, err := game.logic.AddPlayer("a", func() {})
+		require.NoError(t, err)
+		<-game.subscriber.Messages()
+
+		// When
+		messages, err := game.logic.AddPlayer("b", func() {})
+		require.NoError(t, err)
+
+		// Then
+		require.Len(t, messages, 3)
+		assert.Equal(t, protocol.NewEnvelope(protocol.TypeMapCreate, 0, game.logic.WorldMap()), messages[0])
+		assert.Equal(t, protocol.NewEnvelope(protocol.TypePlayerWelcome, 0, player.NewWelcomeMessage("b", []*player.PositionMessage{
+			player.NewPositionMessage(player.NewPlayer("a", 0, 0)),
+			player.NewPositionMessage(player.NewPlayer("b", 0, 0)),
+		})), messages[1])
+
+		assert.Equal(t, protocol.TypeWorldDelta, messages[2].Type)
+		assert.Equal(t, []snapshot.Entity{playerEntity("a", 0, 0), playerEntity("b", 0, 0), zombieEntity("1", 10, 5)},
+			messages[2].Payload.(*snapshot.Snapshot).Entities())
+
+		assert.Equal(t, protocol.NewEnvelope(protocol.TypePlayerPositions, 0, player.NewPositionsMessage(
+			[]*player.PositionMessage{player.NewPositionMessage(player.NewPlayer("b", 0, 0))},
+		)), <-game.subscriber.Messages())
+	})
+
+	t.Run("Should let late joiners subscribe without missing or repeating a tick", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		for i := 0; i < 3; i++ {
+			game.clock.Advance(testTickInterval)
+			<-game.subscriber.Messages()
+		}
+
+		// When
+		var lateJoiner *broadcast.Subscriber
+
+		messages, err := game.logic.AddPlayer("a", func() {
+			lateJoiner = game.broadcaster.AddSubscriber("late joiner")
+		})
+		require.NoError(t, err)
+
+		game.clock.Advance(testTickInterval)
+
+		// Then
+		state := messages[len(messages)-1].Payload.(*snapshot.Snapshot)
+		next := <-lateJoiner.Messages()
+
+		assert.Equal(t, uint64(3), state.Tick)
+		assert.Equal(t, protocol.TypeWorldDelta, next.Type, "the player joining should be covered by the welcome message")
+		assert.Equal(t, state.Tick+1, next.Tick)
+	})
+
+	t.Run("Should move players on the next tick, and include where they went in the snapshot", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		dispatcher := protocol.NewDispatcher()
+		game.logic.RegisterHandlers(dispatcher)
+
+		_, err := game.logic.AddPlayer("a", func() {})
+		require.NoError(t, err)
+		<-game.subscriber.Messages()
+
+		// When
+		require.NoError(t, dispatcher.Dispatch("a", protocol.NewEnvelope(protocol.TypePlayerMove, 0, &player.MoveCommand{DX: 1})))
+		require.NoError(t, dispatcher.Dispatch("a", protocol.NewEnvelope(protocol.TypePlayerMove, 0, &player.MoveCommand{DX: 1, DY: 1})))
+
+		assert.Empty(t, game.subscriber.Messages())
+
+		game.clock.Advance(testTickInterval)
+
+		// Then
+		assert.Contains(t, (<-game.subscriber.Messages()).Payload.(*snapshot.Snapshot).Entities(), playerEntity("a", 1, 1))
+
+		game.clock.Advance(testTickInterval)
+		assert.Contains(t, (<-game.subscriber.Messages()).Payload.(*snapshot.Snapshot).Entities(), playerEntity("a", 1, 1),
+			"player moved without a new command")
+	})
+
+	t.Run("Should kill zombies players attack on the next tick, and tell everyone", func(t *testing.T) {
+		// Given
+		config := gamelogic.DefaultConfig()
+		config.WorldMap = worldmap.New(3, 1)
+
+		game := runGameWithConfig(t, config)
+		defer game.cancelFn()
+
+		dispatcher := protocol.NewDispatcher()
+		game.logic.RegisterHandlers(dispatcher)
+
+		_, err := game.logic.AddPlayer("a", func() {})
+		require.NoError(t, err)
+		<-game.subscriber.Messages()
+
+		// When
+		// The player spawns at (0, 0), and the zombie in the center, at (1, 0)
+		require.NoError(t, dispatcher.Dispatch("a", protocol.NewEnvelope(protocol.TypePlayerAttack, 0, &player.AttackCommand{DX: 1})))
+
+		game.clock.Advance(testTickInterval)
+
+		// Then
+		assert.Equal(t, protocol.NewEnvelope(protocol.TypeZombieStates, 1, zombie.NewZombieStateChanges(
+			[]*zombie.StateChange{zombie.NewZombieStateChange("1", zombie.StateDead)},
+		)), <-game.subscriber.Messages())
+
+		dead := zombieEntity("1", 1, 0)
+		dead.State = string(zombie.StateDead)
+
+		assert.Contains(t, (<-game.subscriber.Messages()).Payload.(*snapshot.Snapshot).Entities(), dead)
+
+		game.clock.Advance(testTickInterval)
+		assert.Equal(t, protocol.TypeWorldDelta, (<-game.subscriber.Messages()).Type, "zombies should only die once")
+	})
+
+	t.Run("Should reject invalid commands and unknown players", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		_, err := game.logic.AddPlayer("a", func() {})
+		require.NoError(t, err)
+
+		// Then
+		assert.Error(t, game.logic.MovePlayer("a", player.MoveCommand{DX: 2}))
+		assert.Error(t, game.logic.MovePlayer("b", player.MoveCommand{DX: 1}))
+		assert.Error(t, game.logic.Attack("a", player.AttackCommand{}))
+		assert.Error(t, game.logic.Attack("b", player.AttackCommand{DX: 1}))
+	})
+
+	t.Run("Should tell everyone when players leave", func(t *testing.T) {
+		// Given
+		game := runGame(t)
+		defer game.cancelFn()
+
+		_, err := game.logic.AddPlayer("a", func() {})
+		require.NoError(t, err)
+		<-game.subscriber.Messages()
+
+		// When
+		require.NoError(t, game.logic.RemovePlayer("a"))
+
+		// Then
+		assert.Equal(t, protocol.NewEnvelope(protocol.TypePlayerLeft, 0, player.NewLeftMessage("a")), <-game.subscriber.Messages())
+	})
+}
+
+func playerEntity(id string, x int, y int) snapshot.Entity {
+	return snapshot.Entity{Key: snapshot.Key{Kind: snapshot.KindPlayer, ID: id}, X: x, Y: y}
+}
@@ -0,0 +1,333 @@
+package gamelogic_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// protoFile is the schema the Protobuf codec must follow
+const protoFile = "../protocol/protocol.proto"
+
+// conformanceCase is a payload, and how it looks in protojson when decoded with the message type in protocol.proto
+type conformanceCase struct {
+	payload   interface{}
+	protoJSON string
+}
+
+// TestProtobufConformance checks that the hand-written Protobuf encoding of every message type matches protocol.proto.
+// Messages encoded by the server are decoded with the schema, and messages encoded with the schema are decoded by the
+// server.
+func TestProtobufConformance(t *testing.T) { //nolint:funlen
+	positions := []*player.PositionMessage{{ID: "a", X: -3, Y: 7}, {ID: "b"}}
+
+	cases := map[string]conformanceCase{
+		protocol.TypeMapCreate: {
+			payload: &worldmap.WorldMap{
+				MinX: -1, MaxX: 2, MinY: 0, MaxY: 2,
+				Tiles: [][]worldmap.TileKind{{worldmap.Wall, worldmap.Floor, worldmap.Door}, {worldmap.Water, worldmap.Wall, worldmap.Floor}},
+			},
+			protoJSON: `{"minX": "-1", "maxX": "2", "maxY": "2", "tiles": [{"tiles": [1, 0, 3]}, {"tiles": [2, 1, 0]}]}`,
+		},
+		protocol.TypeWorldDelta: {
+			payload: &snapshot.Delta{
+				BaseTick: 40,
+				Entities: []snapshot.Entity{
+					{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: "1"}, X: -1, Y: 200, State: string(zombie.StateChasing)},
+				},
+				Removed: []snapshot.Key{{Kind: snapshot.KindPlayer, ID: "2"}},
+			},
+			protoJSON: fmt.Sprintf(
+				`{"baseTick": "40", "entities": [{"key": {"kind": %q, "id": "1"}, "x": "-1", "y": "200", "state": %q}], "removed": [{"kind": %q, "id": "2"}]}`,
+				snapshot.KindZombie, zombie.StateChasing, snapshot.KindPlayer),
+		},
+		protocol.TypeZombieStates: {
+			payload:   zombie.NewZombieStateChanges([]*zombie.StateChange{zombie.NewZombieStateChange("1", zombie.StateChasing)}),
+			protoJSON: fmt.Sprintf(`{"states": [{"id": "1", "state": %q}]}`, zombie.StateChasing),
+		},
+		protocol.TypePlayerWelcome: {
+			payload:   player.NewWelcomeMessage("a", positions),
+			protoJSON: `{"id": "a", "players": [{"id": "a", "x": "-3", "y": "7"}, {"id": "b"}]}`,
+		},
+		protocol.TypePlayerPositions: {
+			payload:   player.NewPositionsMessage(positions),
+			protoJSON: `{"players": [{"id": "a", "x": "-3", "y": "7"}, {"id": "b"}]}`,
+		},
+		protocol.TypePlayerLeft: {
+			payload:   player.NewLeftMessage("a"),
+			protoJSON: `{"id": "a"}`,
+		},
+		protocol.TypeLobbyState: {
+			payload: &lobby.StateMessage{
+				Started:         true,
+				CountdownMillis: 1500,
+				Size:            4,
+				Players:         []*lobby.PlayerState{{ID: "a", Ready: true}, {ID: "b"}},
+			},
+			protoJSON: `{"started": true, "countdownMillis": "1500", "size": "4", "players": [{"id": "a", "ready": true}, {"id": "b"}]}`,
+		},
+		protocol.TypePlayerMove: {
+			payload:   &player.MoveCommand{DX: -1, DY: 1},
+			protoJSON: `{"dx": "-1", "dy": "1"}`,
+		},
+		protocol.TypePlayerAttack: {
+			payload:   &player.AttackCommand{DX: 1, DY: -1},
+			protoJSON: `{"dx": "1", "dy": "-1"}`,
+		},
+		protocol.TypeWorldAck: {
+			payload:   &snapshot.Ack{Tick: 41},
+			protoJSON: `{"tick": "41"}`,
+		},
+		protocol.TypeLobbyReady: {
+			payload:   &lobby.ReadyCommand{Ready: true},
+			protoJSON: `{"ready": true}`,
+		},
+	}
+
+	file, messageTypes := parseProtoFile(t, protoFile)
+	envelopeDescriptor := file.Messages().ByName("Envelope")
+	require.NotNil(t, envelopeDescriptor)
+
+	payloadField := envelopeDescriptor.Fields().ByName("payload")
+	registry := gamelogic.NewRegistry()
+
+	t.Run("Should have a message in protocol.proto for every message type", func(t *testing.T) {
+		for msgType := range cases {
+			assert.Contains(t, messageTypes, msgType)
+			assert.True(t, registry.IsRegistered(msgType), msgType)
+		}
+
+		for msgType := range messageTypes {
+			assert.Contains(t, cases, msgType)
+		}
+	})
+
+	for msgType, c := range cases {
+		msgType, c := msgType, c
+
+		descriptor, ok := messageTypes[msgType]
+		if !ok {
+			continue
+		}
+
+		t.Run(fmt.Sprintf("Should encode %s messages as %s in protocol.proto", msgType, descriptor.Name()), func(t *testing.T) {
+			// Given
+			e := protocol.NewEnvelope(msgType, 42, c.payload).WithSeq(3)
+
+			// When
+			data, err := registry.Encode(protocol.Protobuf, e)
+			require.NoError(t, err)
+
+			envelope := dynamicpb.NewMessage(envelopeDescriptor)
+			require.NoError(t, proto.Unmarshal(data, envelope))
+
+			payload := dynamicpb.NewMessage(descriptor)
+			require.NoError(t, proto.Unmarshal(envelope.Get(payloadField).Bytes(), payload))
+
+			// Then
+			envelope.Clear(payloadField)
+			assertProtoJSON(t, fmt.Sprintf(`{"v": %d, "type": %q, "seq": "3", "tick": "42"}`, protocol.Version, msgType), envelope)
+			assertProtoJSON(t, c.protoJSON, payload)
+		})
+
+		t.Run(fmt.Sprintf("Should decode %s messages encoded as %s in protocol.proto", msgType, descriptor.Name()), func(t *testing.T) {
+			// Given
+			payload := dynamicpb.NewMessage(descriptor)
+			require.NoError(t, protojson.Unmarshal([]byte(c.protoJSON), payload))
+
+			payloadData, err := proto.Marshal(payload)
+			require.NoError(t, err)
+
+			envelope := dynamicpb.NewMessage(envelopeDescriptor)
+			require.NoError(t, protojson.Unmarshal([]byte(fmt.Sprintf(`{"v": %d, "type": %q, "seq": "3", "tick": "42"}`, protocol.Version, msgType)), envelope))
+			envelope.Set(payloadField, protoreflect.ValueOfBytes(payloadData))
+
+			data, err := proto.Marshal(envelope)
+			require.NoError(t, err)
+
+			// When
+			decoded, err := registry.Decode(protocol.Protobuf, data)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, protocol.NewEnvelope(msgType, 42, c.payload).WithSeq(3), decoded)
+		})
+	}
+}
+
+// assertProtoJSON asserts that a message looks like the expected protojson, and has no fields unknown to the schema
+func assertProtoJSON(t *testing.T, expected string, m protoreflect.Message) {
+	actual, err := protojson.Marshal(m.Interface())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, expected, string(actual))
+	assertNoUnknownFields(t, m)
+}
+
+// assertNoUnknownFields asserts that a message, and the messages in it, have no fields unknown to the schema. Fields
+// with a number in the schema, but a different wire type, are unknown too.
+func assertNoUnknownFields(t *testing.T, m protoreflect.Message) {
+	assert.Empty(t, m.GetUnknown(), "unknown fields in %s", m.Descriptor().FullName())
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil:
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				assertNoUnknownFields(t, v.List().Get(i).Message())
+			}
+		default:
+			assertNoUnknownFields(t, v.Message())
+		}
+
+		return true
+	})
+}
+
+// Lines of the subset of the Protobuf language that parseProtoFile understands
+var (
+	protoSyntaxLine  = regexp.MustCompile(`^syntax = "(proto3)";$`)             //nolint:gochecknoglobals
+	protoPackageLine = regexp.MustCompile(`^package ([\w.]+);$`)                //nolint:gochecknoglobals
+	protoMessageLine = regexp.MustCompile(`^message (\w+) {$`)                  //nolint:gochecknoglobals
+	protoFieldLine   = regexp.MustCompile(`^(repeated )?(\w+) (\w+) = (\d+);$`) //nolint:gochecknoglobals
+	protoCommentLine = regexp.MustCompile(`^//\s*(.*)$`)                        //nolint:gochecknoglobals
+	protoTypeComment = regexp.MustCompile(`^[a-z][A-Za-z]*$`)                   //nolint:gochecknoglobals
+	protoScalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{       //nolint:gochecknoglobals
+		"double":   descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+		"float":    descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+		"int32":    descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		"uint32":   descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		"sint32":   descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		"fixed32":  descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+		"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+		"sfixed32": descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+		"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+		"bool":     descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+		"string":   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		"bytes":    descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+	}
+)
+
+// parseProtoFile parses the subset of the Protobuf language that protocol.proto is written in: proto3 messages with
+// scalar, message and repeated fields, one declaration per line. Other lines fail the test, rather than being skipped.
+// It returns the file, and which message each message type is encoded as, from comments naming a message type right
+// above messages.
+func parseProtoFile(t *testing.T, path string) (protoreflect.FileDescriptor, map[string]protoreflect.MessageDescriptor) { //nolint:funlen
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	fileProto := &descriptorpb.FileDescriptorProto{Name: proto.String("protocol.proto")}
+	messageNames := make(map[string]string)
+
+	var message *descriptorpb.DescriptorProto
+
+	var typeComment string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			typeComment = ""
+		case protoCommentLine.MatchString(line):
+			typeComment = ""
+
+			if comment := protoCommentLine.FindStringSubmatch(line)[1]; message == nil && protoTypeComment.MatchString(comment) {
+				typeComment = comment
+			}
+		case protoSyntaxLine.MatchString(line):
+			fileProto.Syntax = proto.String(protoSyntaxLine.FindStringSubmatch(line)[1])
+		case protoPackageLine.MatchString(line):
+			fileProto.Package = proto.String(protoPackageLine.FindStringSubmatch(line)[1])
+		case protoMessageLine.MatchString(line) && message == nil:
+			message = &descriptorpb.DescriptorProto{Name: proto.String(protoMessageLine.FindStringSubmatch(line)[1])}
+			fileProto.MessageType = append(fileProto.MessageType, message)
+
+			if typeComment != "" {
+				messageNames[typeComment] = message.GetName()
+				typeComment = ""
+			}
+		case protoFieldLine.MatchString(line) && message != nil:
+			message.Field = append(message.Field, parseProtoField(t, fileProto.GetPackage(), protoFieldLine.FindStringSubmatch(line)))
+		case line == "}" && message != nil:
+			message = nil
+		default:
+			require.FailNowf(t, "unsupported line", "%s:%d: %s", path, lineNumber, line)
+		}
+	}
+
+	require.NoError(t, scanner.Err())
+
+	file, err := protodesc.NewFile(fileProto, nil)
+	require.NoError(t, err)
+
+	messageTypes := make(map[string]protoreflect.MessageDescriptor)
+
+	for msgType, name := range messageNames {
+		messageTypes[msgType] = file.Messages().ByName(protoreflect.Name(name))
+	}
+
+	return file, messageTypes
+}
+
+// parseProtoField returns the field declared by a line matched by protoFieldLine
+func parseProtoField(t *testing.T, packageName string, match []string) *descriptorpb.FieldDescriptorProto {
+	number, err := strconv.Atoi(match[4])
+	require.NoError(t, err)
+
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(match[3]),
+		JsonName: proto.String(jsonName(match[3])),
+		Number:   proto.Int32(int32(number)),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+
+	if match[1] != "" {
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	}
+
+	if scalarType, ok := protoScalarTypes[match[2]]; ok {
+		field.Type = scalarType.Enum()
+	} else {
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String("." + packageName + "." + match[2])
+	}
+
+	return field
+}
+
+// jsonName returns the lowerCamelCase name protoc gives a field in JSON
+func jsonName(fieldName string) string {
+	parts := strings.Split(fieldName, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.Title(parts[i])
+	}
+
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,102 @@
+package gamelogic
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	zombiePkg "github.com/yngvark/gr-zombie/pkg/zombie"
+)
+
+// Placement decides where zombies are placed when spawned
+type Placement string
+
+const (
+	// PlacementCenter places all zombies in the center of the map
+	PlacementCenter Placement = "center"
+	// PlacementRandom places zombies at random positions
+	PlacementRandom Placement = "random"
+	// PlacementGrid spreads zombies evenly across the map
+	PlacementGrid Placement = "grid"
+)
+
+// ParsePlacement returns the Placement with the given name
+func ParsePlacement(name string) (Placement, error) {
+	placement := Placement(strings.ToLower(name))
+
+	switch placement {
+	case PlacementCenter, PlacementRandom, PlacementGrid:
+		return placement, nil
+	default:
+		return "", fmt.Errorf("not a valid placement: %s", name)
+	}
+}
+
+// SpawnZombies returns count randomly walking zombies placed on the map according to placement. Zombies are only placed
+// on walkable tiles; if the position chosen by placement isn't walkable, the nearest walkable position is used instead.
+// The zombies share rnd, which is also used for random placement.
+func SpawnZombies(m *worldmap.WorldMap, count int, placement Placement, rnd *rand.Rand) ([]*zombiePkg.Zombie, error) {
+	return SpawnZombiesWithBehaviour(m, count, placement, rnd, nil)
+}
+
+// SpawnZombiesWithBehaviour works like SpawnZombies, but gives each zombie the behaviour returned by assign. If assign
+// is nil, the zombies walk randomly.
+func SpawnZombiesWithBehaviour(
+	m *worldmap.WorldMap,
+	count int,
+	placement Placement,
+	rnd *rand.Rand,
+	assign BehaviourAssigner,
+) ([]*zombiePkg.Zombie, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("cannot spawn a negative number of zombies: %d", count)
+	}
+
+	width := m.MaxX - m.MinX
+	height := m.MaxY - m.MinY
+
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("cannot spawn zombies on an empty map")
+	}
+
+	zombies := make([]*zombiePkg.Zombie, 0, count)
+
+	// Grid placement uses the smallest square grid that fits all zombies
+	gridSize := 1
+	for gridSize*gridSize < count {
+		gridSize++
+	}
+
+	for i := 0; i < count; i++ {
+		var x, y int
+
+		switch placement {
+		case PlacementCenter:
+			x, y = width/2, height/2 //nolint:gomnd
+		case PlacementRandom:
+			x, y = rnd.Intn(width), rnd.Intn(height)
+		case PlacementGrid:
+			x = (i%gridSize)*width/gridSize + width/gridSize/2   //nolint:gomnd
+			y = (i/gridSize)*height/gridSize + height/gridSize/2 //nolint:gomnd
+		default:
+			return nil, fmt.Errorf("not a valid placement: %s", placement)
+		}
+
+		walkableX, walkableY, ok := m.NearestWalkable(m.MinX+x, m.MinY+y)
+		if !ok {
+			return nil, fmt.Errorf("cannot spawn zombies on a map without walkable tiles")
+		}
+
+		var behaviour zombiePkg.Behaviour
+		if assign != nil {
+			behaviour = assign(i, worldmap.Point{X: walkableX, Y: walkableY})
+		}
+
+		id := strconv.Itoa(i + 1)
+		zombies = append(zombies, zombiePkg.NewZombieWithBehaviour(id, walkableX, walkableY, m, rnd, behaviour))
+	}
+
+	return zombies, nil
+}
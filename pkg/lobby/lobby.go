@@ -0,0 +1,228 @@
+// Package lobby knows how players get ready for a game, and when the game starts
+package lobby
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+)
+
+const (
+	defaultSize      = 4
+	defaultCountdown = 30 * time.Second
+)
+
+// Config contains settings for Lobby
+type Config struct {
+	// Size is the most players a game can have. If 0, there is no limit.
+	Size int
+	// Countdown is the time from the lobby is created until the game starts, even if not every player is ready. If 0,
+	// the game only starts when every player is ready.
+	Countdown time.Duration
+	// Clock is used for timing the countdown. If nil, the real wall clock is used.
+	Clock clock.Clock
+}
+
+// DefaultConfig returns the default Config
+func DefaultConfig() Config {
+	return Config{
+		Size:      defaultSize,
+		Countdown: defaultCountdown,
+	}
+}
+
+// Lobby keeps track of the players waiting for a game to start, and decides when it starts. The game starts when every
+// player is ready, or when the countdown runs out. Every change is published to the players as a StateMessage. A Lobby
+// is safe for concurrent use.
+type Lobby struct {
+	mutex     sync.Mutex
+	size      int
+	countdown time.Duration
+	clock     clock.Clock
+	publish   func(*StateMessage)
+	// deadline is when the countdown runs out
+	deadline time.Time
+	// players tells whether each player is ready, by player ID
+	players map[string]bool
+	started bool
+	// ready is closed when every player is ready
+	ready       chan struct{}
+	readyClosed bool
+}
+
+// Join adds a player to the lobby. Players can also join after the game has started, if it isn't full.
+func (l *Lobby) Join(id string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.players[id] = false
+	l.publishLocked()
+}
+
+// Leave removes a player from the lobby. If the remaining players are ready, the game starts.
+func (l *Lobby) Leave(id string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.players, id)
+	l.checkReadyLocked()
+	l.publishLocked()
+}
+
+// SetReady marks whether a player is ready for the game to start. When every player is ready, the game starts.
+func (l *Lobby) SetReady(id string, ready bool) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, ok := l.players[id]; !ok {
+		return fmt.Errorf("player %s isn't in the lobby", id)
+	}
+
+	if l.started {
+		return fmt.Errorf("the game has already started")
+	}
+
+	l.players[id] = ready
+	l.checkReadyLocked()
+	l.publishLocked()
+
+	return nil
+}
+
+// Wait blocks until the game should start, and returns true. It returns false if ctx is canceled first.
+func (l *Lobby) Wait(ctx context.Context) bool {
+	var countdownDone <-chan time.Time
+
+	if l.countdown > 0 {
+		ticker := l.clock.NewTicker(l.countdown)
+		defer ticker.Stop()
+
+		countdownDone = ticker.C()
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-l.ready:
+	case <-countdownDone:
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.started = true
+	l.publishLocked()
+
+	return true
+}
+
+// Started returns whether the game has started
+func (l *Lobby) Started() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.started
+}
+
+// Full returns whether the game has as many players as it can have
+func (l *Lobby) Full() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.size > 0 && len(l.players) >= l.size
+}
+
+// Open returns whether the game is waiting for more players, that is, it hasn't started and isn't full
+func (l *Lobby) Open() bool {
+	return !l.Started() && !l.Full()
+}
+
+// Size returns the most players the game can have, or 0 if there is no limit
+func (l *Lobby) Size() int {
+	return l.size
+}
+
+// Countdown returns the configured time from the lobby is created until the game starts
+func (l *Lobby) Countdown() time.Duration {
+	return l.countdown
+}
+
+// State returns the current state of the lobby
+func (l *Lobby) State() *StateMessage {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.stateLocked()
+}
+
+// stateLocked returns the current state of the lobby. The caller must hold the mutex.
+func (l *Lobby) stateLocked() *StateMessage {
+	players := make([]*PlayerState, 0, len(l.players))
+	for id, ready := range l.players {
+		players = append(players, &PlayerState{ID: id, Ready: ready})
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+
+	var countdownMillis int
+
+	if l.countdown > 0 && !l.started {
+		if left := l.deadline.Sub(l.clock.Now()); left > 0 {
+			countdownMillis = int(left.Milliseconds())
+		}
+	}
+
+	return &StateMessage{
+		Started:         l.started,
+		CountdownMillis: countdownMillis,
+		Size:            l.size,
+		Players:         players,
+	}
+}
+
+// publishLocked publishes the current state of the lobby. The caller must hold the mutex, so that states are published
+// in the order they change.
+func (l *Lobby) publishLocked() {
+	l.publish(l.stateLocked())
+}
+
+// checkReadyLocked signals Wait if every player is ready. The caller must hold the mutex.
+func (l *Lobby) checkReadyLocked() {
+	if l.readyClosed || len(l.players) == 0 {
+		return
+	}
+
+	for _, ready := range l.players {
+		if !ready {
+			return
+		}
+	}
+
+	close(l.ready)
+	l.readyClosed = true
+}
+
+// NewLobby returns a new Lobby without players. The countdown starts right away. publish is called with the state of
+// the lobby every time it changes.
+func NewLobby(config Config, publish func(*StateMessage)) *Lobby {
+	lobbyClock := config.Clock
+	if lobbyClock == nil {
+		lobbyClock = clock.New()
+	}
+
+	return &Lobby{
+		size:      config.Size,
+		countdown: config.Countdown,
+		clock:     lobbyClock,
+		publish:   publish,
+		deadline:  lobbyClock.Now().Add(config.Countdown),
+		players:   make(map[string]bool),
+		ready:     make(chan struct{}),
+	}
+}
@@ -0,0 +1,163 @@
+package lobby_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCountdown = 10 * time.Second
+
+//nolint:funlen
+func TestLobby(t *testing.T) {
+	t.Run("Should start the game when every player is ready", func(t *testing.T) {
+		// Given
+		l, _, _ := newLobby(lobby.Config{Size: 2})
+
+		l.Join("a")
+		l.Join("b")
+
+		started := waitInBackground(l)
+
+		// When
+		require.NoError(t, l.SetReady("a", true))
+
+		// Then
+		assert.False(t, l.Started())
+
+		// When
+		require.NoError(t, l.SetReady("b", true))
+
+		// Then
+		assert.True(t, <-started)
+		assert.True(t, l.Started())
+		assert.Error(t, l.SetReady("a", false))
+	})
+
+	t.Run("Should start the game when the remaining players are ready", func(t *testing.T) {
+		// Given
+		l, _, _ := newLobby(lobby.Config{})
+
+		l.Join("a")
+		l.Join("b")
+		require.NoError(t, l.SetReady("a", true))
+
+		started := waitInBackground(l)
+
+		// When
+		l.Leave("b")
+
+		// Then
+		assert.True(t, <-started)
+	})
+
+	t.Run("Should start the game when the countdown runs out", func(t *testing.T) {
+		// Given
+		l, fakeClock, states := newLobby(lobby.Config{Countdown: testCountdown})
+
+		l.Join("a")
+		assert.Equal(t, int(testCountdown.Milliseconds()), (*states)[0].CountdownMillis)
+
+		started := waitInBackground(l)
+
+		fakeClock.WaitForTickers(1)
+
+		// When
+		fakeClock.Advance(testCountdown)
+
+		// Then
+		assert.True(t, <-started)
+		assert.Equal(t, 0, l.State().CountdownMillis)
+	})
+
+	t.Run("Should not start the game when the context is canceled", func(t *testing.T) {
+		// Given
+		l, _, _ := newLobby(lobby.Config{Countdown: testCountdown})
+		ctx, cancelFn := context.WithCancel(context.Background())
+
+		// When
+		cancelFn()
+
+		// Then
+		assert.False(t, l.Wait(ctx))
+		assert.False(t, l.Started())
+	})
+
+	t.Run("Should publish the state every time it changes", func(t *testing.T) {
+		// Given
+		l, _, states := newLobby(lobby.Config{Size: 2})
+
+		// When
+		l.Join("b")
+		l.Join("a")
+		require.NoError(t, l.SetReady("b", true))
+		l.Leave("a")
+
+		// Then
+		assert.Equal(t, []*lobby.StateMessage{
+			{Size: 2, Players: []*lobby.PlayerState{{ID: "b"}}},
+			{Size: 2, Players: []*lobby.PlayerState{{ID: "a"}, {ID: "b"}}},
+			{Size: 2, Players: []*lobby.PlayerState{{ID: "a"}, {ID: "b", Ready: true}}},
+			{Size: 2, Players: []*lobby.PlayerState{{ID: "b", Ready: true}}},
+		}, *states)
+	})
+
+	t.Run("Should be open until full or started", func(t *testing.T) {
+		// Given
+		l, _, _ := newLobby(lobby.Config{Size: 2})
+
+		// Then
+		l.Join("a")
+		assert.True(t, l.Open())
+
+		l.Join("b")
+		assert.True(t, l.Full())
+		assert.False(t, l.Open())
+
+		l.Leave("b")
+		assert.True(t, l.Open())
+
+		require.NoError(t, l.SetReady("a", true))
+		require.True(t, l.Wait(context.Background()))
+		assert.False(t, l.Open())
+	})
+
+	t.Run("Should reject players that aren't in the lobby getting ready", func(t *testing.T) {
+		// Given
+		l, _, _ := newLobby(lobby.Config{})
+
+		// Then
+		assert.Error(t, l.SetReady("a", true))
+	})
+}
+
+// newLobby returns a Lobby with a fake clock, and the states it has published
+func newLobby(config lobby.Config) (*lobby.Lobby, *clock.Fake, *[]*lobby.StateMessage) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	config.Clock = fakeClock
+
+	states := make([]*lobby.StateMessage, 0)
+
+	l := lobby.NewLobby(config, func(state *lobby.StateMessage) {
+		states = append(states, state)
+	})
+
+	return l, fakeClock, &states
+}
+
+// waitInBackground calls Wait in the background, and returns a channel receiving its result
+func waitInBackground(l *lobby.Lobby) <-chan bool {
+	started := make(chan bool, 1)
+
+	go func() {
+		started <- l.Wait(context.Background())
+	}()
+
+	return started
+}
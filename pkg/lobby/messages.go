@@ -0,0 +1,41 @@
+package lobby
+
+import (
+	"fmt"
+
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+)
+
+// StateMessage tells the players in a lobby who is ready, and when the game starts
+type StateMessage struct {
+	Started bool `json:"started"`
+	// CountdownMillis is the time left until the game starts regardless of who is ready, in milliseconds. It's 0 if
+	// there's no countdown.
+	CountdownMillis int `json:"countdownMillis"`
+	// Size is the most players the game can have, or 0 if there is no limit
+	Size    int            `json:"size"`
+	Players []*PlayerState `json:"players"`
+}
+
+// PlayerState tells whether a player is ready for the game to start
+type PlayerState struct {
+	ID    string `json:"id"`
+	Ready bool   `json:"ready"`
+}
+
+// ReadyCommand tells whether the client's player is ready for the game to start
+type ReadyCommand struct {
+	Ready bool `json:"ready"`
+}
+
+// RegisterHandlers makes the dispatcher pass messages from clients on to the lobby
+func (l *Lobby) RegisterHandlers(d *protocol.Dispatcher) {
+	d.Handle(protocol.TypeLobbyReady, func(clientID string, payload interface{}) error {
+		command, ok := payload.(*ReadyCommand)
+		if !ok {
+			return fmt.Errorf("unexpected payload type %T", payload)
+		}
+
+		return l.SetReady(clientID, command.Ready)
+	})
+}
@@ -0,0 +1,99 @@
+package lobby
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf field numbers, see protocol.proto
+const (
+	protoStateStartedField         protowire.Number = 1
+	protoStateCountdownMillisField protowire.Number = 2
+	protoStateSizeField            protowire.Number = 3
+	protoStatePlayersField         protowire.Number = 4
+
+	protoPlayerIDField    protowire.Number = 1
+	protoPlayerReadyField protowire.Number = 2
+
+	protoReadyField protowire.Number = 1
+)
+
+// AppendProto implements protocol.ProtoPayload
+func (m *StateMessage) AppendProto(b []byte) []byte {
+	if m.Started {
+		b = protocol.AppendProtoUint(b, protoStateStartedField, 1)
+	}
+
+	b = protocol.AppendProtoInt(b, protoStateCountdownMillisField, m.CountdownMillis)
+	b = protocol.AppendProtoInt(b, protoStateSizeField, m.Size)
+
+	for _, p := range m.Players {
+		b = protocol.AppendProtoMessage(b, protoStatePlayersField, p.appendProto)
+	}
+
+	return b
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (m *StateMessage) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoStateStartedField:
+			m.Started = f.Uint() != 0
+		case protoStateCountdownMillisField:
+			m.CountdownMillis = f.Int()
+		case protoStateSizeField:
+			m.Size = f.Int()
+		case protoStatePlayersField:
+			p := &PlayerState{}
+			m.Players = append(m.Players, p)
+
+			return p.unmarshalProto(f.Bytes())
+		}
+
+		return nil
+	})
+}
+
+func (p *PlayerState) appendProto(b []byte) []byte {
+	b = protocol.AppendProtoString(b, protoPlayerIDField, p.ID)
+
+	if p.Ready {
+		b = protocol.AppendProtoUint(b, protoPlayerReadyField, 1)
+	}
+
+	return b
+}
+
+func (p *PlayerState) unmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoPlayerIDField:
+			p.ID = f.String()
+		case protoPlayerReadyField:
+			p.Ready = f.Uint() != 0
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (c *ReadyCommand) AppendProto(b []byte) []byte {
+	if c.Ready {
+		b = protocol.AppendProtoUint(b, protoReadyField, 1)
+	}
+
+	return b
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (c *ReadyCommand) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number == protoReadyField {
+			c.Ready = f.Uint() != 0
+		}
+
+		return nil
+	})
+}
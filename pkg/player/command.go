@@ -0,0 +1,40 @@
+package player
+
+import (
+	"fmt"
+)
+
+// MoveCommand asks for the player to be moved one step. DX and DY must be -1, 0 or 1.
+type MoveCommand struct {
+	DX int `json:"dx"`
+	DY int `json:"dy"`
+}
+
+// Validate returns an error if the command is invalid
+func (c MoveCommand) Validate() error {
+	if !isStep(c.DX) || !isStep(c.DY) {
+		return fmt.Errorf("players can only move one step at a time, not (%d, %d)", c.DX, c.DY)
+	}
+
+	return nil
+}
+
+// AttackCommand asks for the player to attack the neighbouring tile in the direction (DX, DY), killing any zombies there.
+// DX and DY must be -1, 0 or 1, and not both 0.
+type AttackCommand struct {
+	DX int `json:"dx"`
+	DY int `json:"dy"`
+}
+
+// Validate returns an error if the command is invalid
+func (c AttackCommand) Validate() error {
+	if !isStep(c.DX) || !isStep(c.DY) || (c.DX == 0 && c.DY == 0) {
+		return fmt.Errorf("players can only attack neighbouring tiles, not (%d, %d)", c.DX, c.DY)
+	}
+
+	return nil
+}
+
+func isStep(d int) bool {
+	return d >= -1 && d <= 1
+}
@@ -0,0 +1,27 @@
+// Package player knows how to manage the players in a game
+package player
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+)
+
+// Player is a person playing the game, through a connected client
+type Player struct {
+	ID string
+	X  int
+	Y  int
+}
+
+// Position returns where the Player is
+func (p *Player) Position() worldmap.Point {
+	return worldmap.Point{X: p.X, Y: p.Y}
+}
+
+// NewPlayer returns a new Player
+func NewPlayer(id string, x int, y int) *Player {
+	return &Player{
+		ID: id,
+		X:  x,
+		Y:  y,
+	}
+}
@@ -0,0 +1,56 @@
+package player
+
+// PositionMessage tells where a player is
+type PositionMessage struct {
+	ID string `json:"id"`
+	X  int    `json:"x"`
+	Y  int    `json:"y"`
+}
+
+// NewPositionMessage returns a new PositionMessage
+func NewPositionMessage(p *Player) *PositionMessage {
+	return &PositionMessage{
+		ID: p.ID,
+		X:  p.X,
+		Y:  p.Y,
+	}
+}
+
+// PositionsMessage is a batch of player positions, for instance the players that moved during a tick
+type PositionsMessage struct {
+	Players []*PositionMessage `json:"players"`
+}
+
+// NewPositionsMessage returns a new PositionsMessage
+func NewPositionsMessage(players []*PositionMessage) *PositionsMessage {
+	return &PositionsMessage{
+		Players: players,
+	}
+}
+
+// LeftMessage tells that a player left the game
+type LeftMessage struct {
+	ID string `json:"id"`
+}
+
+// NewLeftMessage returns a new LeftMessage
+func NewLeftMessage(id string) *LeftMessage {
+	return &LeftMessage{
+		ID: id,
+	}
+}
+
+// WelcomeMessage is sent to a client when its player joins the game. It tells which player is the client's, and where
+// all the players are.
+type WelcomeMessage struct {
+	ID      string             `json:"id"`
+	Players []*PositionMessage `json:"players"`
+}
+
+// NewWelcomeMessage returns a new WelcomeMessage
+func NewWelcomeMessage(id string, players []*PositionMessage) *WelcomeMessage {
+	return &WelcomeMessage{
+		ID:      id,
+		Players: players,
+	}
+}
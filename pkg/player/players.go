@@ -0,0 +1,210 @@
+package player
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+)
+
+// Players keeps track of the players in a game, and the commands they have sent since the last tick. Players is safe
+// for concurrent use, so players can join, leave and send commands from their connections while the game runs.
+type Players struct {
+	mutex    sync.Mutex
+	worldMap *worldmap.WorldMap
+	players  map[string]*Player
+	// commands contains the latest command from each player since the commands were last applied
+	commands map[string]MoveCommand
+	// attacks contains the latest attack from each player since the attacks were last applied
+	attacks map[string]AttackCommand
+}
+
+// Join adds a player with the given ID to the game, and returns it. Players spawn on the walkable tile nearest the top
+// left corner of the map.
+func (p *Players) Join(id string) (*Player, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.players[id]; ok {
+		return nil, fmt.Errorf("player %s has already joined", id)
+	}
+
+	x, y, ok := p.worldMap.NearestWalkable(p.worldMap.MinX, p.worldMap.MinY)
+	if !ok {
+		return nil, fmt.Errorf("cannot spawn players on a map without walkable tiles")
+	}
+
+	player := NewPlayer(id, x, y)
+	p.players[id] = player
+
+	return copyOf(player), nil
+}
+
+// Leave removes the player with the given ID from the game. It returns false if there is no such player.
+func (p *Players) Leave(id string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.players[id]; !ok {
+		return false
+	}
+
+	delete(p.players, id)
+	delete(p.commands, id)
+	delete(p.attacks, id)
+
+	return true
+}
+
+// Command queues a command from the player with the given ID, to be applied by the next call to ApplyCommands. A newer
+// command replaces an older one that isn't applied yet.
+func (p *Players) Command(id string, command MoveCommand) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.players[id]; !ok {
+		return fmt.Errorf("no player with ID %s", id)
+	}
+
+	if err := command.Validate(); err != nil {
+		return err
+	}
+
+	p.commands[id] = command
+
+	return nil
+}
+
+// ApplyCommands moves the players according to their queued commands, and returns the players that moved, sorted by
+// ID. Moves to tiles that can't be walked on are ignored, and so are diagonal moves that would cut a corner.
+func (p *Players) ApplyCommands() []*Player {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	moved := make([]*Player, 0)
+
+	for id, command := range p.commands {
+		player := p.players[id]
+		x, y := player.X+command.DX, player.Y+command.DY
+
+		if (x != player.X || y != player.Y) && p.canMove(player, command) {
+			player.X, player.Y = x, y
+			moved = append(moved, copyOf(player))
+		}
+
+		delete(p.commands, id)
+	}
+
+	sortByID(moved)
+
+	return moved
+}
+
+// canMove returns true if the player can make the move. Like the pathfinder, a diagonal move requires both orthogonal
+// neighbours to be walkable, so players can't squeeze between walls that touch at a corner.
+func (p *Players) canMove(player *Player, command MoveCommand) bool {
+	if !p.worldMap.IsWalkable(player.X+command.DX, player.Y+command.DY) {
+		return false
+	}
+
+	if command.DX != 0 && command.DY != 0 {
+		return p.worldMap.IsWalkable(player.X+command.DX, player.Y) && p.worldMap.IsWalkable(player.X, player.Y+command.DY)
+	}
+
+	return true
+}
+
+// Attack queues an attack from the player with the given ID, to be applied by the next call to ApplyAttacks. A newer
+// attack replaces an older one that isn't applied yet.
+func (p *Players) Attack(id string, command AttackCommand) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.players[id]; !ok {
+		return fmt.Errorf("no player with ID %s", id)
+	}
+
+	if err := command.Validate(); err != nil {
+		return err
+	}
+
+	p.attacks[id] = command
+
+	return nil
+}
+
+// ApplyAttacks returns the positions the players attack with their queued attacks, sorted by the attacking players' IDs.
+// The attacks are from where the players are when ApplyAttacks is called.
+func (p *Players) ApplyAttacks() []worldmap.Point {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	attackers := make([]string, 0, len(p.attacks))
+	for id := range p.attacks {
+		attackers = append(attackers, id)
+	}
+
+	sort.Strings(attackers)
+
+	attacked := make([]worldmap.Point, 0, len(attackers))
+
+	for _, id := range attackers {
+		player, command := p.players[id], p.attacks[id]
+		attacked = append(attacked, worldmap.Point{X: player.X + command.DX, Y: player.Y + command.DY})
+
+		delete(p.attacks, id)
+	}
+
+	return attacked
+}
+
+// All returns all the players, sorted by ID
+func (p *Players) All() []*Player {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	players := make([]*Player, 0, len(p.players))
+	for _, player := range p.players {
+		players = append(players, copyOf(player))
+	}
+
+	sortByID(players)
+
+	return players
+}
+
+// Positions returns the positions of all the players
+func (p *Players) Positions() []worldmap.Point {
+	players := p.All()
+
+	positions := make([]worldmap.Point, 0, len(players))
+	for _, player := range players {
+		positions = append(positions, player.Position())
+	}
+
+	return positions
+}
+
+// copyOf returns a copy of a player, which can be handed out without races with later changes to the original
+func copyOf(player *Player) *Player {
+	c := *player
+
+	return &c
+}
+
+func sortByID(players []*Player) {
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+}
+
+// NewPlayers returns a new Players for a game played on the given map
+func NewPlayers(m *worldmap.WorldMap) *Players {
+	return &Players{
+		worldMap: m,
+		players:  make(map[string]*Player),
+		commands: make(map[string]MoveCommand),
+		attacks:  make(map[string]AttackCommand),
+	}
+}
@@ -0,0 +1,196 @@
+package player_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveCommand(t *testing.T) {
+	t.Run("Should accept steps to neighbouring tiles", func(t *testing.T) {
+		for _, command := range []player.MoveCommand{{DX: 1, DY: -1}, {DX: -1}, {DY: 1}, {}} {
+			assert.NoError(t, command.Validate(), command)
+		}
+	})
+
+	t.Run("Should reject longer moves", func(t *testing.T) {
+		for _, command := range []player.MoveCommand{{DX: 2}, {DY: -5}, {DX: 1, DY: 3}} {
+			assert.Error(t, command.Validate(), command)
+		}
+	})
+}
+
+func TestAttackCommand(t *testing.T) {
+	t.Run("Should accept attacks on neighbouring tiles", func(t *testing.T) {
+		for _, command := range []player.AttackCommand{{DX: 1, DY: -1}, {DX: -1}, {DY: 1}} {
+			assert.NoError(t, command.Validate(), command)
+		}
+	})
+
+	t.Run("Should reject attacks on the player's own tile, or further away", func(t *testing.T) {
+		for _, command := range []player.AttackCommand{{}, {DX: 2}, {DX: 1, DY: -2}} {
+			assert.Error(t, command.Validate(), command)
+		}
+	})
+}
+
+//nolint:funlen
+func TestPlayers(t *testing.T) {
+	newPlayers := func(t *testing.T) *player.Players {
+		m, err := worldmap.ParseASCII(strings.NewReader("#...\n.#..\n"))
+		require.NoError(t, err)
+
+		return player.NewPlayers(m)
+	}
+
+	t.Run("Should spawn players on the walkable tile nearest the top left corner", func(t *testing.T) {
+		players := newPlayers(t)
+
+		p, err := players.Join("a")
+		require.NoError(t, err)
+
+		assert.Equal(t, worldmap.Point{X: 1, Y: 0}, p.Position())
+	})
+
+	t.Run("Should not let a player join twice", func(t *testing.T) {
+		players := newPlayers(t)
+
+		_, err := players.Join("a")
+		require.NoError(t, err)
+
+		_, err = players.Join("a")
+		assert.Error(t, err)
+	})
+
+	t.Run("Should apply the latest command of each player, once", func(t *testing.T) {
+		// Given
+		players := newPlayers(t)
+
+		for _, id := range []string{"b", "a", "c"} {
+			_, err := players.Join(id)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, players.Command("b", player.MoveCommand{DX: -1}))
+		require.NoError(t, players.Command("b", player.MoveCommand{DX: 1}))
+		require.NoError(t, players.Command("a", player.MoveCommand{DX: 1}))
+
+		// When
+		moved := players.ApplyCommands()
+
+		// Then
+		assert.Equal(t, []*player.Player{player.NewPlayer("a", 2, 0), player.NewPlayer("b", 2, 0)}, moved)
+		assert.Empty(t, players.ApplyCommands())
+	})
+
+	t.Run("Should not move players onto tiles that can't be walked on", func(t *testing.T) {
+		// Given
+		players := newPlayers(t)
+
+		_, err := players.Join("a")
+		require.NoError(t, err)
+
+		// When
+		for _, command := range []player.MoveCommand{{DX: -1}, {DY: 1}, {DY: -1}} {
+			require.NoError(t, players.Command("a", command))
+
+			// Then
+			assert.Empty(t, players.ApplyCommands())
+		}
+
+		assert.Equal(t, []worldmap.Point{{X: 1, Y: 0}}, players.Positions())
+	})
+
+	t.Run("Should not let players cut corners when moving diagonally", func(t *testing.T) {
+		// Given
+		players := newPlayers(t)
+
+		_, err := players.Join("a")
+		require.NoError(t, err)
+
+		// When
+		for _, command := range []player.MoveCommand{{DX: -1, DY: 1}, {DX: 1, DY: 1}} {
+			require.NoError(t, players.Command("a", command))
+
+			// Then
+			assert.Empty(t, players.ApplyCommands())
+		}
+
+		assert.Equal(t, []worldmap.Point{{X: 1, Y: 0}}, players.Positions())
+	})
+
+	t.Run("Should move players diagonally when both orthogonal neighbours can be walked on", func(t *testing.T) {
+		// Given
+		players := newPlayers(t)
+
+		_, err := players.Join("a")
+		require.NoError(t, err)
+
+		require.NoError(t, players.Command("a", player.MoveCommand{DX: 1}))
+		players.ApplyCommands()
+
+		// When
+		require.NoError(t, players.Command("a", player.MoveCommand{DX: 1, DY: 1}))
+		moved := players.ApplyCommands()
+
+		// Then
+		assert.Equal(t, []*player.Player{player.NewPlayer("a", 3, 1)}, moved)
+	})
+
+	t.Run("Should forget players that left", func(t *testing.T) {
+		// Given
+		players := newPlayers(t)
+
+		_, err := players.Join("a")
+		require.NoError(t, err)
+		require.NoError(t, players.Command("a", player.MoveCommand{DX: 1}))
+
+		// When
+		assert.True(t, players.Leave("a"))
+
+		// Then
+		assert.False(t, players.Leave("a"))
+		assert.Empty(t, players.All())
+		assert.Empty(t, players.ApplyCommands())
+		assert.Error(t, players.Command("a", player.MoveCommand{DX: 1}))
+	})
+
+	t.Run("Should reject invalid commands", func(t *testing.T) {
+		players := newPlayers(t)
+
+		_, err := players.Join("a")
+		require.NoError(t, err)
+
+		assert.Error(t, players.Command("a", player.MoveCommand{DX: 2}))
+		assert.Error(t, players.Attack("a", player.AttackCommand{}))
+		assert.Error(t, players.Attack("b", player.AttackCommand{DX: 1}))
+	})
+
+	t.Run("Should attack from where players are, once per queued attack", func(t *testing.T) {
+		// Given
+		players := newPlayers(t)
+
+		_, err := players.Join("b")
+		require.NoError(t, err)
+		_, err = players.Join("a")
+		require.NoError(t, err)
+
+		// Players spawn at (1, 0)
+		require.NoError(t, players.Command("a", player.MoveCommand{DX: 1}))
+		players.ApplyCommands()
+
+		// When
+		require.NoError(t, players.Attack("b", player.AttackCommand{DX: -1}))
+		require.NoError(t, players.Attack("a", player.AttackCommand{DX: 1}))
+		require.NoError(t, players.Attack("a", player.AttackCommand{DX: -1, DY: 1}))
+
+		// Then
+		assert.Equal(t, []worldmap.Point{{X: 1, Y: 1}, {X: 0, Y: 0}}, players.ApplyAttacks())
+		assert.Empty(t, players.ApplyAttacks())
+	})
+}
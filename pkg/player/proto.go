@@ -0,0 +1,154 @@
+package player
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf field numbers, see protocol.proto
+const (
+	protoIDField protowire.Number = 1
+
+	protoPositionXField protowire.Number = 2
+	protoPositionYField protowire.Number = 3
+
+	protoPositionsPlayersField protowire.Number = 1
+	protoWelcomePlayersField   protowire.Number = 2
+
+	protoMoveDXField protowire.Number = 1
+	protoMoveDYField protowire.Number = 2
+)
+
+// AppendProto implements protocol.ProtoPayload
+func (m *PositionMessage) AppendProto(b []byte) []byte {
+	b = protocol.AppendProtoString(b, protoIDField, m.ID)
+	b = protocol.AppendProtoInt(b, protoPositionXField, m.X)
+
+	return protocol.AppendProtoInt(b, protoPositionYField, m.Y)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (m *PositionMessage) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoIDField:
+			m.ID = f.String()
+		case protoPositionXField:
+			m.X = f.Int()
+		case protoPositionYField:
+			m.Y = f.Int()
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (m *PositionsMessage) AppendProto(b []byte) []byte {
+	return appendProtoPositions(b, protoPositionsPlayersField, m.Players)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (m *PositionsMessage) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number != protoPositionsPlayersField {
+			return nil
+		}
+
+		return unmarshalProtoPosition(f, &m.Players)
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (m *LeftMessage) AppendProto(b []byte) []byte {
+	return protocol.AppendProtoString(b, protoIDField, m.ID)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (m *LeftMessage) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number == protoIDField {
+			m.ID = f.String()
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (m *WelcomeMessage) AppendProto(b []byte) []byte {
+	b = protocol.AppendProtoString(b, protoIDField, m.ID)
+
+	return appendProtoPositions(b, protoWelcomePlayersField, m.Players)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (m *WelcomeMessage) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoIDField:
+			m.ID = f.String()
+		case protoWelcomePlayersField:
+			return unmarshalProtoPosition(f, &m.Players)
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (c *MoveCommand) AppendProto(b []byte) []byte {
+	b = protocol.AppendProtoInt(b, protoMoveDXField, c.DX)
+
+	return protocol.AppendProtoInt(b, protoMoveDYField, c.DY)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (c *MoveCommand) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoMoveDXField:
+			c.DX = f.Int()
+		case protoMoveDYField:
+			c.DY = f.Int()
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (c *AttackCommand) AppendProto(b []byte) []byte {
+	b = protocol.AppendProtoInt(b, protoMoveDXField, c.DX)
+
+	return protocol.AppendProtoInt(b, protoMoveDYField, c.DY)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (c *AttackCommand) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoMoveDXField:
+			c.DX = f.Int()
+		case protoMoveDYField:
+			c.DY = f.Int()
+		}
+
+		return nil
+	})
+}
+
+func appendProtoPositions(b []byte, num protowire.Number, positions []*PositionMessage) []byte {
+	for _, position := range positions {
+		b = protocol.AppendProtoMessage(b, num, position.AppendProto)
+	}
+
+	return b
+}
+
+func unmarshalProtoPosition(f protocol.ProtoField, positions *[]*PositionMessage) error {
+	position := &PositionMessage{}
+	*positions = append(*positions, position)
+
+	return position.UnmarshalProto(f.Bytes())
+}
@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"fmt"
+)
+
+// Codec encodes and decodes messages in some wire format
+type Codec interface {
+	// Name is the name clients use to choose the codec
+	Name() string
+	// Binary returns whether encoded messages are binary. Binary messages are sent as websocket binary frames.
+	Binary() bool
+	// Marshal encodes a message
+	Marshal(e *Envelope) ([]byte, error)
+	// Unmarshal decodes a message. The payload is decoded into the value newPayload returns for the message type.
+	Unmarshal(data []byte, newPayload func(msgType string) (interface{}, error)) (*Envelope, error)
+}
+
+// Codecs supported by the server
+var (
+	// JSON encodes messages as JSON, with the field names of the payloads' json tags
+	JSON Codec = jsonCodec{} //nolint:gochecknoglobals
+	// MessagePack encodes messages as MessagePack, with the same field names as JSON
+	MessagePack Codec = msgpackCodec{} //nolint:gochecknoglobals
+	// Protobuf encodes messages as Protocol Buffers, as described in protocol.proto. Payloads must implement
+	// ProtoPayload.
+	Protobuf Codec = protobufCodec{} //nolint:gochecknoglobals
+)
+
+// CodecByName returns the codec with the given name
+func CodecByName(name string) (Codec, error) {
+	for _, codec := range []Codec{JSON, MessagePack, Protobuf} {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown codec: %q", name)
+}
@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Binary() bool {
+	return false
+}
+
+func (jsonCodec) Marshal(e *Envelope) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (jsonCodec) Unmarshal(data []byte, newPayload func(msgType string) (interface{}, error)) (*Envelope, error) {
+	var raw struct {
+		Envelope
+		Payload json.RawMessage `json:"payload"`
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding message: %w", err)
+	}
+
+	payload, err := newPayload(raw.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw.Payload) > 0 {
+		err = json.Unmarshal(raw.Payload, payload)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s payload: %w", raw.Type, err)
+		}
+	}
+
+	e := raw.Envelope
+	e.Payload = payload
+
+	return &e, nil
+}
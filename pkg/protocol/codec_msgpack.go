@@ -0,0 +1,78 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackStructTag makes MessagePack use the same field names as JSON, so clients can share code between the formats
+const msgpackStructTag = "json"
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string {
+	return "msgpack"
+}
+
+func (msgpackCodec) Binary() bool {
+	return true
+}
+
+func (msgpackCodec) Marshal(e *Envelope) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag(msgpackStructTag)
+	enc.UseCompactInts(true)
+
+	err := enc.Encode(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, newPayload func(msgType string) (interface{}, error)) (*Envelope, error) {
+	var raw struct {
+		Version int                `json:"v"`
+		Type    string             `json:"type"`
+		Seq     uint64             `json:"seq"`
+		Tick    uint64             `json:"tick"`
+		Payload msgpack.RawMessage `json:"payload"`
+	}
+
+	err := newMsgpackDecoder(data).Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding message: %w", err)
+	}
+
+	payload, err := newPayload(raw.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw.Payload) > 0 {
+		err = newMsgpackDecoder(raw.Payload).Decode(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s payload: %w", raw.Type, err)
+		}
+	}
+
+	return &Envelope{
+		Version: raw.Version,
+		Type:    raw.Type,
+		Seq:     raw.Seq,
+		Tick:    raw.Tick,
+		Payload: payload,
+	}, nil
+}
+
+func newMsgpackDecoder(data []byte) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag(msgpackStructTag)
+
+	return dec
+}
@@ -0,0 +1,89 @@
+package protocol
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of Envelope in protocol.proto
+const (
+	envelopeVersionField protowire.Number = iota + 1
+	envelopeTypeField
+	envelopeSeqField
+	envelopeTickField
+	envelopePayloadField
+)
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string {
+	return "protobuf"
+}
+
+func (protobufCodec) Binary() bool {
+	return true
+}
+
+func (protobufCodec) Marshal(e *Envelope) ([]byte, error) {
+	payload, ok := e.Payload.(ProtoPayload)
+	if !ok && e.Payload != nil {
+		return nil, fmt.Errorf("payload of %s message can't be encoded as protobuf: %T", e.Type, e.Payload)
+	}
+
+	b := AppendProtoInt(nil, envelopeVersionField, e.Version)
+	b = AppendProtoString(b, envelopeTypeField, e.Type)
+	b = AppendProtoUint(b, envelopeSeqField, e.Seq)
+	b = AppendProtoUint(b, envelopeTickField, e.Tick)
+
+	if payload != nil {
+		b = AppendProtoMessage(b, envelopePayloadField, payload.AppendProto)
+	}
+
+	return b, nil
+}
+
+func (protobufCodec) Unmarshal(data []byte, newPayload func(msgType string) (interface{}, error)) (*Envelope, error) {
+	e := &Envelope{}
+
+	var payloadData []byte
+
+	err := RangeProtoFields(data, func(f ProtoField) error {
+		switch f.Number {
+		case envelopeVersionField:
+			e.Version = f.Int()
+		case envelopeTypeField:
+			e.Type = f.String()
+		case envelopeSeqField:
+			e.Seq = f.Uint()
+		case envelopeTickField:
+			e.Tick = f.Uint()
+		case envelopePayloadField:
+			payloadData = f.Bytes()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decoding message: %w", err)
+	}
+
+	payload, err := newPayload(e.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	protoPayload, ok := payload.(ProtoPayload)
+	if !ok {
+		return nil, fmt.Errorf("payload of %s message can't be decoded from protobuf: %T", e.Type, payload)
+	}
+
+	err = protoPayload.UnmarshalProto(payloadData)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s payload: %w", e.Type, err)
+	}
+
+	e.Payload = payload
+
+	return e, nil
+}
@@ -0,0 +1,54 @@
+package protocol
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Handler handles a message from a client. The payload has the Go type registered for the message type.
+type Handler func(clientID string, payload interface{}) error
+
+// Dispatcher passes messages from clients on to the handlers of their message types. A Dispatcher is safe for
+// concurrent use.
+type Dispatcher struct {
+	mutex    sync.RWMutex
+	handlers map[string]Handler
+}
+
+// Handle sets the handler of a message type, replacing any previous handler
+func (d *Dispatcher) Handle(msgType string, handler Handler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.handlers[msgType] = handler
+}
+
+// Dispatch passes a message from a client on to the handler of its message type. Messages with an unknown type, or
+// from an unsupported protocol version, are rejected.
+func (d *Dispatcher) Dispatch(clientID string, e *Envelope) error {
+	if e.Version != Version {
+		return fmt.Errorf("unsupported protocol version %d of %s message, expected %d", e.Version, e.Type, Version)
+	}
+
+	d.mutex.RLock()
+	handler, ok := d.handlers[e.Type]
+	d.mutex.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("no handler for message type: %q", e.Type)
+	}
+
+	err := handler(clientID, e.Payload)
+	if err != nil {
+		return fmt.Errorf("handling %s message: %w", e.Type, err)
+	}
+
+	return nil
+}
+
+// NewDispatcher returns a new Dispatcher without any handlers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers: make(map[string]Handler),
+	}
+}
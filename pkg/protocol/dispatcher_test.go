@@ -0,0 +1,58 @@
+package protocol_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Should pass messages on to the handler of their type", func(t *testing.T) {
+		// Given
+		dispatcher := protocol.NewDispatcher()
+
+		var gotClientID string
+
+		var gotPayload interface{}
+
+		dispatcher.Handle("test", func(clientID string, payload interface{}) error {
+			gotClientID, gotPayload = clientID, payload
+
+			return nil
+		})
+
+		payload := &testPayload{Text: "hello"}
+
+		// When
+		err := dispatcher.Dispatch("a", protocol.NewEnvelope("test", 0, payload))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "a", gotClientID)
+		assert.Same(t, payload, gotPayload)
+	})
+
+	t.Run("Should return errors from handlers", func(t *testing.T) {
+		dispatcher := protocol.NewDispatcher()
+		handlerErr := errors.New("failed")
+
+		dispatcher.Handle("test", func(string, interface{}) error { return handlerErr })
+
+		assert.True(t, errors.Is(dispatcher.Dispatch("a", protocol.NewEnvelope("test", 0, nil)), handlerErr))
+	})
+
+	t.Run("Should reject unknown message types and unsupported versions", func(t *testing.T) {
+		dispatcher := protocol.NewDispatcher()
+		dispatcher.Handle("test", func(string, interface{}) error { return nil })
+
+		oldVersion := protocol.NewEnvelope("test", 0, nil)
+		oldVersion.Version = protocol.Version - 1
+
+		assert.Error(t, dispatcher.Dispatch("a", protocol.NewEnvelope("unknown", 0, nil)))
+		assert.Error(t, dispatcher.Dispatch("a", oldVersion))
+	})
+}
@@ -0,0 +1,48 @@
+// Package protocol knows the messages sent between the game server and its clients, and how to encode and decode them
+package protocol
+
+// Version is the version of the protocol. It must be increased when messages change in ways that old clients can't
+// handle.
+const Version = 1
+
+// Envelope wraps every message sent between the server and clients
+type Envelope struct {
+	// Version is the protocol version the message was created with
+	Version int `json:"v"`
+	// Type tells what kind of message this is, and thereby what the payload is
+	Type string `json:"type"`
+	// Seq numbers the messages sent on a connection, starting at 1. Receivers can use it to detect missing messages.
+	Seq uint64 `json:"seq"`
+	// Tick is the server's game tick when the message was created
+	Tick uint64 `json:"tick"`
+	// Payload is the message itself. Its Go type is decided by the message type, see Registry.
+	Payload interface{} `json:"payload"`
+}
+
+// WithSeq returns a copy of the Envelope with the given sequence number. Envelopes are shared between connections, so
+// they must not be changed once created.
+func (e *Envelope) WithSeq(seq uint64) *Envelope {
+	c := *e
+	c.Seq = seq
+
+	return &c
+}
+
+// WithPayload returns a copy of the Envelope with the given payload
+func (e *Envelope) WithPayload(payload interface{}) *Envelope {
+	c := *e
+	c.Payload = payload
+
+	return &c
+}
+
+// NewEnvelope returns a new Envelope for the current protocol Version. The sequence number is set when the message is
+// sent.
+func NewEnvelope(msgType string, tick uint64, payload interface{}) *Envelope {
+	return &Envelope{
+		Version: Version,
+		Type:    msgType,
+		Tick:    tick,
+		Payload: payload,
+	}
+}
@@ -0,0 +1,155 @@
+package protocol
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtoPayload is a payload that can be encoded with the Protobuf codec. The message formats are described in
+// protocol.proto.
+type ProtoPayload interface {
+	// AppendProto appends the payload, encoded as protobuf, to b
+	AppendProto(b []byte) []byte
+	// UnmarshalProto decodes the payload from protobuf
+	UnmarshalProto(b []byte) error
+}
+
+// AppendProtoInt appends a signed integer field, encoded as sint64. Zero values are left out, like protobuf does.
+func AppendProtoInt(b []byte, num protowire.Number, v int) []byte {
+	if v == 0 {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+
+	return protowire.AppendVarint(b, protowire.EncodeZigZag(int64(v)))
+}
+
+// AppendProtoUint appends an unsigned integer field, encoded as uint64. Zero values are left out.
+func AppendProtoUint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+
+	return protowire.AppendVarint(b, v)
+}
+
+// AppendProtoString appends a string field. Empty strings are left out.
+func AppendProtoString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+
+	return protowire.AppendString(b, s)
+}
+
+// AppendProtoMessage appends a nested message field, encoded by appendMessage
+func AppendProtoMessage(b []byte, num protowire.Number, appendMessage func(b []byte) []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+
+	// The length goes before the message, but isn't known until the message is encoded. Encoding the message first
+	// and moving it to make room for the length avoids encoding it into a separate buffer.
+	start := len(b)
+	b = appendMessage(b)
+	size := len(b) - start
+	lengthSize := protowire.SizeVarint(uint64(size))
+
+	for i := 0; i < lengthSize; i++ {
+		b = append(b, 0)
+	}
+
+	copy(b[start+lengthSize:], b[start:start+size])
+	protowire.AppendVarint(b[:start], uint64(size))
+
+	return b
+}
+
+// ProtoField is a field of an encoded protobuf message
+type ProtoField struct {
+	Number protowire.Number
+	Type   protowire.Type
+	varint uint64
+	bytes  []byte
+}
+
+// Int returns the value of a sint64 field
+func (f ProtoField) Int() int {
+	return int(protowire.DecodeZigZag(f.varint))
+}
+
+// Uint returns the value of an unsigned integer field
+func (f ProtoField) Uint() uint64 {
+	return f.varint
+}
+
+// String returns the value of a string field
+func (f ProtoField) String() string {
+	return string(f.bytes)
+}
+
+// Bytes returns the value of a bytes field or nested message
+func (f ProtoField) Bytes() []byte {
+	return f.bytes
+}
+
+// Uints returns the values of a repeated unsigned integer field, whether packed or not
+func (f ProtoField) Uints(values []uint64) ([]uint64, error) {
+	if f.Type == protowire.VarintType {
+		return append(values, f.varint), nil
+	}
+
+	for b := f.bytes; len(b) > 0; {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		values = append(values, v)
+		b = b[n:]
+	}
+
+	return values, nil
+}
+
+// RangeProtoFields calls fn for each varint, bytes and nested message field of an encoded protobuf message. Fields of
+// other wire types aren't used by the protocol, and are skipped.
+func RangeProtoFields(b []byte, fn func(f ProtoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		b = b[n:]
+		f := ProtoField{Number: num, Type: typ}
+
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		b = b[n:]
+
+		if typ != protowire.VarintType && typ != protowire.BytesType {
+			continue
+		}
+
+		err := fn(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package protocol
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NewPayload returns a pointer to a new, empty payload of some message type, which a payload can be decoded into
+type NewPayload func() interface{}
+
+// Registry knows the registered message types, and encodes and decodes messages of those types. A Registry is safe for
+// concurrent use.
+type Registry struct {
+	mutex    sync.RWMutex
+	payloads map[string]NewPayload
+}
+
+// Register registers a message type, with a function returning new payloads of that type
+func (r *Registry) Register(msgType string, newPayload NewPayload) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.payloads[msgType] = newPayload
+}
+
+// IsRegistered returns whether a message type is registered
+func (r *Registry) IsRegistered(msgType string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, ok := r.payloads[msgType]
+
+	return ok
+}
+
+// Encode encodes a message with a codec. Messages must have a registered type.
+func (r *Registry) Encode(codec Codec, e *Envelope) ([]byte, error) {
+	if !r.IsRegistered(e.Type) {
+		return nil, fmt.Errorf("unknown message type: %q", e.Type)
+	}
+
+	data, err := codec.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s message as %s: %w", e.Type, codec.Name(), err)
+	}
+
+	return data, nil
+}
+
+// Decode decodes a message with a codec. The payload is decoded into the Go type registered for the message type.
+func (r *Registry) Decode(codec Codec, data []byte) (*Envelope, error) {
+	return codec.Unmarshal(data, r.newPayload)
+}
+
+func (r *Registry) newPayload(msgType string) (interface{}, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	newPayload, ok := r.payloads[msgType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type: %q", msgType)
+	}
+
+	return newPayload(), nil
+}
+
+// NewRegistry returns a new Registry without any registered message types
+func NewRegistry() *Registry {
+	return &Registry{
+		payloads: make(map[string]NewPayload),
+	}
+}
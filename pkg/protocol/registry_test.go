@@ -0,0 +1,109 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	Text string `json:"text"`
+}
+
+func (p *testPayload) AppendProto(b []byte) []byte {
+	return protocol.AppendProtoString(b, 1, p.Text)
+}
+
+func (p *testPayload) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number == 1 {
+			p.Text = f.String()
+		}
+
+		return nil
+	})
+}
+
+func newTestRegistry() *protocol.Registry {
+	registry := protocol.NewRegistry()
+	registry.Register("test", func() interface{} { return &testPayload{} })
+
+	return registry
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Should encode messages as JSON", func(t *testing.T) {
+		data, err := newTestRegistry().Encode(protocol.JSON, protocol.NewEnvelope("test", 3, &testPayload{Text: "hello"}).WithSeq(7))
+		require.NoError(t, err)
+
+		assert.Equal(t, `{"v":1,"type":"test","seq":7,"tick":3,"payload":{"text":"hello"}}`, string(data))
+	})
+
+	for _, codec := range []protocol.Codec{protocol.JSON, protocol.MessagePack, protocol.Protobuf} {
+		codec := codec
+
+		t.Run("Should decode messages encoded as "+codec.Name()+" into the registered payload type", func(t *testing.T) {
+			// Given
+			registry := newTestRegistry()
+			e := protocol.NewEnvelope("test", 3, &testPayload{Text: "hello"}).WithSeq(7)
+
+			// When
+			data, err := registry.Encode(codec, e)
+			require.NoError(t, err)
+
+			decoded, err := registry.Decode(codec, data)
+			require.NoError(t, err)
+
+			// Then
+			assert.Equal(t, e, decoded)
+		})
+
+		t.Run("Should reject unknown message types as "+codec.Name(), func(t *testing.T) {
+			registry := newTestRegistry()
+
+			_, err := registry.Encode(codec, protocol.NewEnvelope("unknown", 0, &testPayload{}))
+			assert.Error(t, err)
+
+			other := protocol.NewRegistry()
+			other.Register("unknown", func() interface{} { return &testPayload{} })
+
+			data, err := other.Encode(codec, protocol.NewEnvelope("unknown", 0, &testPayload{}))
+			require.NoError(t, err)
+
+			_, err = registry.Decode(codec, data)
+			assert.Error(t, err)
+		})
+
+		t.Run("Should reject invalid "+codec.Name()+" messages", func(t *testing.T) {
+			_, err := newTestRegistry().Decode(codec, []byte{0xff, 0xff, 0xff})
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("Should reject JSON messages with invalid payloads", func(t *testing.T) {
+		_, err := newTestRegistry().Decode(protocol.JSON, []byte(`{"v":1,"type":"test","payload":"not an object"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Should not encode payloads without protobuf support as protobuf", func(t *testing.T) {
+		registry := protocol.NewRegistry()
+		registry.Register("string", func() interface{} { return new(string) })
+
+		_, err := registry.Encode(protocol.Protobuf, protocol.NewEnvelope("string", 0, "hello"))
+		assert.Error(t, err)
+	})
+}
+
+func TestCodecByName(t *testing.T) {
+	for _, codec := range []protocol.Codec{protocol.JSON, protocol.MessagePack, protocol.Protobuf} {
+		found, err := protocol.CodecByName(codec.Name())
+		require.NoError(t, err)
+		assert.Equal(t, codec, found)
+	}
+
+	_, err := protocol.CodecByName("xml")
+	assert.Error(t, err)
+}
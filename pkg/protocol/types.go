@@ -0,0 +1,31 @@
+package protocol
+
+// Message types sent from the server to clients
+const (
+	// TypeMapCreate tells clients what the world map looks like
+	TypeMapCreate = "mapCreate"
+	// TypeWorldDelta tells how the zombies and players changed since the last state the client acknowledged
+	TypeWorldDelta = "worldDelta"
+	// TypeZombieStates tells which zombies changed state during a tick
+	TypeZombieStates = "zombieStates"
+	// TypePlayerWelcome tells a client which player is its own, and where all the players are
+	TypePlayerWelcome = "playerWelcome"
+	// TypePlayerPositions tells where players are, for instance after they joined or moved
+	TypePlayerPositions = "playerPositions"
+	// TypePlayerLeft tells that a player left the game
+	TypePlayerLeft = "playerLeft"
+	// TypeLobbyState tells who in the room is ready, and when the game starts
+	TypeLobbyState = "lobbyState"
+)
+
+// Message types sent from clients to the server
+const (
+	// TypePlayerMove asks for the client's player to be moved
+	TypePlayerMove = "playerMove"
+	// TypePlayerAttack asks for the client's player to attack a neighbouring tile
+	TypePlayerAttack = "playerAttack"
+	// TypeWorldAck acknowledges that the client has the world state of a tick
+	TypeWorldAck = "worldAck"
+	// TypeLobbyReady tells whether the client's player is ready for the game to start
+	TypeLobbyReady = "lobbyReady"
+)
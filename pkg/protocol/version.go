@@ -0,0 +1,43 @@
+package protocol
+
+import "fmt"
+
+// SupportedVersions are the protocol versions the server can speak, preferred version first
+var SupportedVersions = []int{Version} //nolint:gochecknoglobals
+
+// Websocket close codes sent to clients the server can't talk with. Codes 4000-4999 are reserved for applications by
+// RFC 6455.
+const (
+	// CloseUnsupportedVersion is sent to clients that don't speak any supported protocol version
+	CloseUnsupportedVersion = 4001
+	// CloseUnsupportedCodec is sent to clients asking for a codec the server doesn't have
+	CloseUnsupportedCodec = 4002
+)
+
+// Subprotocol returns the name of the websocket subprotocol for a protocol version, for instance "gr-zombie.v1". Clients
+// offer the versions they speak in the Sec-WebSocket-Protocol header, and the server picks one of them.
+func Subprotocol(version int) string {
+	return fmt.Sprintf("gr-zombie.v%d", version)
+}
+
+// Subprotocols returns the websocket subprotocols of SupportedVersions, preferred subprotocol first
+func Subprotocols() []string {
+	subprotocols := make([]string, len(SupportedVersions))
+
+	for i, version := range SupportedVersions {
+		subprotocols[i] = Subprotocol(version)
+	}
+
+	return subprotocols
+}
+
+// Supports returns whether the version is one of SupportedVersions
+func Supports(version int) bool {
+	for _, supported := range SupportedVersions {
+		if version == supported {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+)
+
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// Backoff tells how long to wait before retrying an operation that failed, like reading from a broker. The wait doubles
+// with each failure in a row, from Min up to Max.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+	// Clock is used for waiting. If nil, the real wall clock is used.
+	Clock clock.Clock
+	next  time.Duration
+}
+
+// Next returns how long to wait before the next retry, and doubles the wait after that
+func (b *Backoff) Next() time.Duration {
+	if b.next < b.Min {
+		b.next = b.Min
+	}
+
+	wait := b.next
+
+	b.next *= 2
+	if b.next > b.Max {
+		b.next = b.Max
+	}
+
+	return wait
+}
+
+// Wait waits before the next retry. It returns false if the context is done before then.
+func (b *Backoff) Wait(ctx context.Context) bool {
+	waitClock := b.Clock
+	if waitClock == nil {
+		waitClock = clock.New()
+	}
+
+	// The ticker is stopped after its first tick, so it works as a timer
+	ticker := waitClock.NewTicker(b.Next())
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Reset makes the next wait Min again. Call it when the operation succeeds.
+func (b *Backoff) Reset() {
+	b.next = 0
+}
+
+// NewBackoff returns a Backoff waiting from 100 milliseconds up to 10 seconds, measured by the given clock. If the clock
+// is nil, the real wall clock is used.
+func NewBackoff(c clock.Clock) *Backoff {
+	return &Backoff{
+		Min:   defaultMinBackoff,
+		Max:   defaultMaxBackoff,
+		Clock: c,
+	}
+}
@@ -0,0 +1,82 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/clock"
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("Should double the wait after each failure, up to Max", func(t *testing.T) {
+		// Given
+		backoff := &pubsub.Backoff{Min: time.Second, Max: 5 * time.Second}
+
+		// When
+		var waits []time.Duration
+		for i := 0; i < 5; i++ {
+			waits = append(waits, backoff.Next())
+		}
+
+		// Then
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, waits)
+	})
+
+	t.Run("Should wait Min again after Reset", func(t *testing.T) {
+		// Given
+		backoff := &pubsub.Backoff{Min: time.Second, Max: 5 * time.Second}
+		backoff.Next()
+		backoff.Next()
+
+		// When
+		backoff.Reset()
+
+		// Then
+		assert.Equal(t, time.Second, backoff.Next())
+	})
+
+	t.Run("Should wait for as long as Next would return, measured by the clock", func(t *testing.T) {
+		// Given
+		fakeClock := clock.NewFake(time.Unix(0, 0))
+		backoff := &pubsub.Backoff{Min: time.Second, Max: 5 * time.Second, Clock: fakeClock}
+		backoff.Next()
+
+		retried := make(chan bool)
+
+		// When
+		go func() {
+			retried <- backoff.Wait(context.Background())
+		}()
+
+		fakeClock.WaitForTickers(1)
+		fakeClock.Advance(2*time.Second - time.Nanosecond)
+
+		// Then
+		select {
+		case <-retried:
+			assert.FailNow(t, "stopped waiting too early")
+		default:
+		}
+
+		fakeClock.Advance(time.Nanosecond)
+		assert.True(t, <-retried)
+	})
+
+	t.Run("Should stop waiting when the context is done", func(t *testing.T) {
+		// Given
+		backoff := pubsub.NewBackoff(clock.NewFake(time.Unix(0, 0)))
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		cancelFn()
+
+		// When
+		retry := backoff.Wait(ctx)
+
+		// Then
+		assert.False(t, retry)
+	})
+}
@@ -1,32 +1,181 @@
 // Package broadcast knows how to broadcast messages to subscribers
 package broadcast
 
-import "go.uber.org/zap"
+import (
+	"sync"
 
-// Broadcaster is used for sending (broadcasting) messages to a number of subscribers
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"go.uber.org/zap"
+)
+
+// Broadcaster is used for sending (broadcasting) messages to a number of subscribers. Broadcasting never blocks: each
+// Subscriber has its own bounded queue, and what happens when it's full is decided by the configured OverflowPolicy.
+// A Broadcaster is safe for concurrent use. Every subscriber receives the same message, so messages must not be changed
+// once broadcast.
 type Broadcaster struct {
-	subscribers []chan<- string
+	mutex       sync.RWMutex
+	subscribers []*Subscriber
 	log         *zap.SugaredLogger
+	config      Config
 }
 
-// AddSubscriber adds a Subscriber to its list of subscribers
-func (b *Broadcaster) AddSubscriber(subscriber chan<- string) {
-	b.subscribers = append(b.subscribers, subscriber)
+// AddSubscriber adds a Subscriber to its list of subscribers. The id is used for identifying the subscriber in logs and
+// statistics. The subscriber receives messages published to the given topics, or to all topics if none are given.
+// Remember to call RemoveSubscriber when done.
+func (b *Broadcaster) AddSubscriber(id string, topics ...string) *Subscriber {
+	s := newSubscriber(id, b.config.QueueSize, topics)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.subscribers = append(b.subscribers, s)
+
+	return s
 }
 
-// BroadCast sends a message to all Subscriber-s
-func (b *Broadcaster) BroadCast(msg string) error {
-	for _, subscriber := range b.subscribers {
-		subscriber <- msg
+// RemoveSubscriber removes a Subscriber from its list of subscribers. After RemoveSubscriber returns, no more messages
+// will be sent to the subscriber. Removing a subscriber that isn't added is a no-op.
+func (b *Broadcaster) RemoveSubscriber(subscriber *Subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for i, s := range b.subscribers {
+		if s != subscriber {
+			continue
+		}
+
+		s.close()
+
+		remaining := make([]*Subscriber, 0, len(b.subscribers)-1)
+		remaining = append(remaining, b.subscribers[:i]...)
+		remaining = append(remaining, b.subscribers[i+1:]...)
+		b.subscribers = remaining
+
+		if dropped := s.Dropped(); dropped > 0 {
+			b.log.Infof("Removed subscriber %s, which had %d dropped messages", s.ID(), dropped)
+		}
+
+		return
 	}
+}
+
+// BroadCast sends a message to all Subscriber-s, regardless of which topics they are subscribed to
+func (b *Broadcaster) BroadCast(msg *protocol.Envelope) error {
+	b.disconnect(b.sendToAll(msg, func(*Subscriber) bool { return true }))
 
 	return nil
 }
 
-// New returns a new Broadcaster
+// Publish sends a message to the Subscriber-s that are subscribed to the given topic
+func (b *Broadcaster) Publish(topic string, msg *protocol.Envelope) error {
+	b.disconnect(b.sendToAll(msg, func(s *Subscriber) bool { return s.IsSubscribedTo(topic) }))
+
+	return nil
+}
+
+func (b *Broadcaster) disconnect(slowSubscribers []*Subscriber) {
+	for _, s := range slowSubscribers {
+		b.log.Warnf("Disconnecting slow subscriber %s", s.ID())
+		b.RemoveSubscriber(s)
+	}
+}
+
+// sendToAll sends a message to all subscribers accepted by the filter, and returns the subscribers that should be
+// disconnected
+func (b *Broadcaster) sendToAll(msg *protocol.Envelope, filter func(*Subscriber) bool) []*Subscriber {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var slowSubscribers []*Subscriber
+
+	for _, s := range b.subscribers {
+		if !filter(s) {
+			continue
+		}
+
+		if !b.send(s, msg) {
+			slowSubscribers = append(slowSubscribers, s)
+		}
+	}
+
+	return slowSubscribers
+}
+
+// send sends a message to a subscriber. It returns false if the subscriber should be disconnected.
+func (b *Broadcaster) send(s *Subscriber, msg *protocol.Envelope) bool {
+	for {
+		if s.isClosed() {
+			return true
+		}
+
+		select {
+		case s.queue <- msg:
+			return true
+		default:
+		}
+
+		// The subscriber's queue is full
+		switch b.config.OverflowPolicy {
+		case DropNewest:
+			s.drop()
+			return true
+		case Disconnect:
+			s.drop()
+			return false
+		default: // DropOldest
+			select {
+			case <-s.queue:
+				s.drop()
+			default:
+				// The subscriber read a message in the meantime, so there's room for one more
+			}
+		}
+	}
+}
+
+// Stats returns statistics for each subscriber
+func (b *Broadcaster) Stats() []SubscriberStats {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(b.subscribers))
+
+	for _, s := range b.subscribers {
+		stats = append(stats, SubscriberStats{
+			ID:      s.ID(),
+			Queued:  len(s.queue),
+			Dropped: s.Dropped(),
+		})
+	}
+
+	return stats
+}
+
+// SubscriberStats contains statistics for a Subscriber
+type SubscriberStats struct {
+	ID      string
+	Queued  int
+	Dropped uint64
+}
+
+// New returns a new Broadcaster with the default Config
 func New(logger *zap.SugaredLogger) *Broadcaster {
+	return NewWithConfig(logger, DefaultConfig())
+}
+
+// NewWithConfig returns a new Broadcaster
+func NewWithConfig(logger *zap.SugaredLogger, config Config) *Broadcaster {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+
 	return &Broadcaster{
 		log:         logger,
-		subscribers: make([]chan<- string, 0),
+		config:      config,
+		subscribers: make([]*Subscriber, 0),
 	}
 }
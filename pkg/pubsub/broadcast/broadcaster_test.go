@@ -2,8 +2,10 @@ package broadcast_test
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/yngvark/gr-zombie/pkg/protocol"
 	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
 
 	"github.com/stretchr/testify/require"
@@ -16,21 +18,322 @@ func TestPubSub(t *testing.T) {
 		"Should send message to listeners", func(t *testing.T) {
 			// Given
 			var broadcaster *broadcast.Broadcaster = broadcast.New(nil)
-			testSubscriber := make(chan string)
-
-			broadcaster.AddSubscriber(testSubscriber)
+			testSubscriber := broadcaster.AddSubscriber("test")
 
 			// When
 			fmt.Println("sending")
 			go func() {
-				err := broadcaster.BroadCast("YO")
+				err := broadcaster.BroadCast(message("YO"))
 				require.NoError(t, err)
 			}()
 
 			// Then
 			fmt.Println("receiving")
-			lastMsgReceived := <-testSubscriber
+			lastMsgReceived := (<-testSubscriber.Messages()).Payload
 			assert.Equal(t, "YO", lastMsgReceived)
 		},
 	)
 }
+
+func TestRemoveSubscriber(t *testing.T) {
+	t.Run("Should not send messages to removed subscribers", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.New(nil)
+		removedSubscriber := broadcaster.AddSubscriber("removed")
+
+		broadcaster.RemoveSubscriber(removedSubscriber)
+
+		// When
+		err := broadcaster.BroadCast(message("YO"))
+		require.NoError(t, err)
+
+		// Then
+		assert.Empty(t, removedSubscriber.Messages())
+	})
+
+	t.Run("Should close Done when removed", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.New(nil)
+		subscriber := broadcaster.AddSubscriber("removed")
+
+		// When
+		broadcaster.RemoveSubscriber(subscriber)
+
+		// Then
+		_, open := <-subscriber.Done()
+		assert.False(t, open)
+	})
+
+	t.Run("Should keep broadcasting to other subscribers when one is removed", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.New(nil)
+		activeSubscriber := broadcaster.AddSubscriber("active")
+		goneSubscriber := broadcaster.AddSubscriber("gone")
+
+		broadcaster.RemoveSubscriber(goneSubscriber)
+
+		// When
+		err := broadcaster.BroadCast(message("YO"))
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, "YO", (<-activeSubscriber.Messages()).Payload)
+	})
+
+	t.Run("Should ignore removing a subscriber that isn't added", func(t *testing.T) {
+		broadcaster := broadcast.New(nil)
+		otherBroadcaster := broadcast.New(nil)
+
+		assert.NotPanics(t, func() {
+			broadcaster.RemoveSubscriber(otherBroadcaster.AddSubscriber("other"))
+		})
+	})
+}
+
+//nolint:funlen
+func TestOverflowPolicy(t *testing.T) {
+	testCases := []struct {
+		name             string
+		policy           broadcast.OverflowPolicy
+		expectedMessages []string
+		expectedDropped  uint64
+		expectedDone     bool
+	}{
+		{
+			name:             "Should drop oldest messages",
+			policy:           broadcast.DropOldest,
+			expectedMessages: []string{"3", "4"},
+			expectedDropped:  2,
+		},
+		{
+			name:             "Should drop newest messages",
+			policy:           broadcast.DropNewest,
+			expectedMessages: []string{"1", "2"},
+			expectedDropped:  2,
+		},
+		{
+			name:             "Should disconnect slow subscriber",
+			policy:           broadcast.Disconnect,
+			expectedMessages: []string{"1", "2"},
+			expectedDropped:  1,
+			expectedDone:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			broadcaster := broadcast.NewWithConfig(nil, broadcast.Config{
+				QueueSize:      2,
+				OverflowPolicy: tc.policy,
+			})
+			slowSubscriber := broadcaster.AddSubscriber("slow")
+
+			// When
+			for _, msg := range []string{"1", "2", "3", "4"} {
+				err := broadcaster.BroadCast(message(msg))
+				require.NoError(t, err)
+			}
+
+			// Then
+			assert.Equal(t, tc.expectedMessages, readQueued(slowSubscriber))
+			assert.Equal(t, tc.expectedDropped, slowSubscriber.Dropped())
+			assert.Equal(t, tc.expectedDone, isDone(slowSubscriber))
+		})
+	}
+
+	t.Run("Should not let a slow subscriber affect other subscribers", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.NewWithConfig(nil, broadcast.Config{
+			QueueSize:      1,
+			OverflowPolicy: broadcast.DropNewest,
+		})
+		slowSubscriber := broadcaster.AddSubscriber("slow")
+		fastSubscriber := broadcaster.AddSubscriber("fast")
+
+		// When
+		for _, msg := range []string{"1", "2", "3"} {
+			err := broadcaster.BroadCast(message(msg))
+			require.NoError(t, err)
+
+			// Then
+			assert.Equal(t, msg, (<-fastSubscriber.Messages()).Payload)
+		}
+
+		assert.Equal(t, []string{"1"}, readQueued(slowSubscriber))
+	})
+
+	t.Run("Should count dropped messages per subscriber", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.NewWithConfig(nil, broadcast.Config{
+			QueueSize:      1,
+			OverflowPolicy: broadcast.DropNewest,
+		})
+		broadcaster.AddSubscriber("slow")
+		fastSubscriber := broadcaster.AddSubscriber("fast")
+
+		// When
+		for _, msg := range []string{"1", "2", "3"} {
+			err := broadcaster.BroadCast(message(msg))
+			require.NoError(t, err)
+
+			<-fastSubscriber.Messages()
+		}
+
+		// Then
+		assert.Equal(t, []broadcast.SubscriberStats{
+			{ID: "slow", Queued: 1, Dropped: 2},
+			{ID: "fast", Queued: 0, Dropped: 0},
+		}, broadcaster.Stats())
+	})
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	t.Run("Should parse policy names", func(t *testing.T) {
+		for name, expected := range map[string]broadcast.OverflowPolicy{
+			"drop-oldest": broadcast.DropOldest,
+			"drop-newest": broadcast.DropNewest,
+			"Disconnect":  broadcast.Disconnect,
+		} {
+			policy, err := broadcast.ParseOverflowPolicy(name)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, policy)
+		}
+	})
+
+	t.Run("Should fail on unknown policy names", func(t *testing.T) {
+		_, err := broadcast.ParseOverflowPolicy("block")
+		assert.Error(t, err)
+	})
+}
+
+// message returns a test message with the given text as payload
+func message(text string) *protocol.Envelope {
+	return protocol.NewEnvelope("test", 0, text)
+}
+
+// readQueued returns the payloads of the messages queued for the subscriber
+func readQueued(subscriber *broadcast.Subscriber) []string {
+	msgs := make([]string, 0)
+
+	for {
+		select {
+		case msg := <-subscriber.Messages():
+			msgs = append(msgs, msg.Payload.(string))
+		default:
+			return msgs
+		}
+	}
+}
+
+func isDone(subscriber *broadcast.Subscriber) bool {
+	select {
+	case <-subscriber.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// TestConcurrentUse is most useful when run with the race detector: go test -race
+func TestConcurrentUse(t *testing.T) {
+	t.Run("Should handle subscribers coming and going while broadcasting", func(t *testing.T) {
+		// Given
+		const subscriberCount = 500
+
+		broadcaster := broadcast.NewWithConfig(nil, broadcast.Config{
+			QueueSize:      4,
+			OverflowPolicy: broadcast.Disconnect,
+		})
+
+		stopBroadcasting := make(chan bool)
+		broadcastingStopped := make(chan bool)
+
+		go func() {
+			defer close(broadcastingStopped)
+
+			for {
+				select {
+				case <-stopBroadcasting:
+					return
+				default:
+					assert.NoError(t, broadcaster.BroadCast(message("tick")))
+					_ = broadcaster.Stats()
+				}
+			}
+		}()
+
+		// When
+		var wg sync.WaitGroup
+
+		for i := 0; i < subscriberCount; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				subscriber := broadcaster.AddSubscriber(fmt.Sprintf("subscriber-%d", i))
+
+				for received := 0; received < 3; received++ {
+					select {
+					case <-subscriber.Messages():
+					case <-subscriber.Done():
+						return
+					}
+				}
+
+				broadcaster.RemoveSubscriber(subscriber)
+			}(i)
+		}
+
+		wg.Wait()
+		close(stopBroadcasting)
+		<-broadcastingStopped
+
+		// Then
+		assert.Empty(t, broadcaster.Stats())
+	})
+}
+
+func TestTopics(t *testing.T) {
+	t.Run("Should publish messages only to subscribers of the topic", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.New(nil)
+		chatSubscriber := broadcaster.AddSubscriber("chat", broadcast.TopicChat)
+		mapSubscriber := broadcaster.AddSubscriber("minimap", broadcast.TopicMap, broadcast.TopicZombies)
+		allSubscriber := broadcaster.AddSubscriber("all")
+
+		// When
+		require.NoError(t, broadcaster.Publish(broadcast.TopicChat, message("hi")))
+		require.NoError(t, broadcaster.Publish(broadcast.TopicZombies, message("zombie moved")))
+		require.NoError(t, broadcaster.Publish(broadcast.RoomTopic("abc"), message("room created")))
+
+		// Then
+		assert.Equal(t, []string{"hi"}, readQueued(chatSubscriber))
+		assert.Equal(t, []string{"zombie moved"}, readQueued(mapSubscriber))
+		assert.Equal(t, []string{"hi", "zombie moved", "room created"}, readQueued(allSubscriber))
+	})
+
+	t.Run("Should broadcast messages to subscribers of any topic", func(t *testing.T) {
+		// Given
+		broadcaster := broadcast.New(nil)
+		chatSubscriber := broadcaster.AddSubscriber("chat", broadcast.TopicChat)
+
+		// When
+		require.NoError(t, broadcaster.BroadCast(message("server shutting down")))
+
+		// Then
+		assert.Equal(t, []string{"server shutting down"}, readQueued(chatSubscriber))
+	})
+
+	t.Run("Should return subscribed topics", func(t *testing.T) {
+		broadcaster := broadcast.New(nil)
+
+		assert.Equal(t,
+			[]string{"map", "room/abc"},
+			broadcaster.AddSubscriber("1", broadcast.RoomTopic("abc"), broadcast.TopicMap).Topics())
+		assert.Empty(t, broadcaster.AddSubscriber("2").Topics())
+	})
+}
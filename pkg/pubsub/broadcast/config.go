@@ -0,0 +1,49 @@
+package broadcast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OverflowPolicy decides what to do when a message is broadcasted to a Subscriber whose queue is full
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest queued message to make room for the new one
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the new message
+	DropNewest
+	// Disconnect removes the Subscriber from the Broadcaster
+	Disconnect
+)
+
+const defaultQueueSize = 64
+
+// Config contains settings for a Broadcaster
+type Config struct {
+	// QueueSize is the number of messages that can be queued for each Subscriber
+	QueueSize      int
+	OverflowPolicy OverflowPolicy
+}
+
+// DefaultConfig returns the default Config
+func DefaultConfig() Config {
+	return Config{
+		QueueSize:      defaultQueueSize,
+		OverflowPolicy: DropOldest,
+	}
+}
+
+// ParseOverflowPolicy returns the OverflowPolicy with the given name: drop-oldest, drop-newest or disconnect
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	switch strings.ToLower(name) {
+	case "drop-oldest":
+		return DropOldest, nil
+	case "drop-newest":
+		return DropNewest, nil
+	case "disconnect":
+		return Disconnect, nil
+	default:
+		return DropOldest, fmt.Errorf("not a valid overflow policy: %s", name)
+	}
+}
@@ -0,0 +1,94 @@
+package broadcast
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+)
+
+// Subscriber receives messages from a Broadcaster through a bounded queue
+type Subscriber struct {
+	id      string
+	queue   chan *protocol.Envelope
+	dropped uint64
+	// topics contains the topics subscribed to. If empty, the Subscriber is subscribed to all topics.
+	topics map[string]bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// ID returns the Subscriber's ID
+func (s *Subscriber) ID() string {
+	return s.id
+}
+
+// Topics returns the topics the Subscriber is subscribed to. An empty result means all topics.
+func (s *Subscriber) Topics() []string {
+	topics := make([]string, 0, len(s.topics))
+
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
+
+// IsSubscribedTo returns whether the Subscriber receives messages published to the given topic
+func (s *Subscriber) IsSubscribedTo(topic string) bool {
+	return len(s.topics) == 0 || s.topics[topic]
+}
+
+// Messages returns the channel to read broadcasted messages from
+func (s *Subscriber) Messages() <-chan *protocol.Envelope {
+	return s.queue
+}
+
+// Done returns a channel that is closed when the Subscriber is removed from the Broadcaster, for instance because it
+// was too slow to read its messages.
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Dropped returns the number of messages that have been dropped because the Subscriber's queue was full
+func (s *Subscriber) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscriber) drop() {
+	atomic.AddUint64(&s.dropped, 1)
+}
+
+func (s *Subscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *Subscriber) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func newSubscriber(id string, queueSize int, topics []string) *Subscriber {
+	topicSet := make(map[string]bool, len(topics))
+
+	for _, topic := range topics {
+		topicSet[topic] = true
+	}
+
+	return &Subscriber{
+		id:     id,
+		queue:  make(chan *protocol.Envelope, queueSize),
+		topics: topicSet,
+		done:   make(chan struct{}),
+	}
+}
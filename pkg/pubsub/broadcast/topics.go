@@ -0,0 +1,24 @@
+package broadcast
+
+// Topics used by the game
+const (
+	// TopicWorld is for the state of the world, like where zombies and players are
+	TopicWorld = "world"
+	// TopicZombies is for zombie events, like zombies changing state. Where zombies are is on TopicWorld.
+	TopicZombies = "zombies"
+	// TopicPlayers is for player events, like players joining and leaving
+	TopicPlayers = "players"
+	// TopicMap is for world map events
+	TopicMap = "map"
+	// TopicLobby is for lobby events, like players getting ready and the game starting
+	TopicLobby = "lobby"
+	// TopicChat is for chat messages
+	TopicChat = "chat"
+)
+
+const roomTopicPrefix = "room/"
+
+// RoomTopic returns the topic for messages concerning the room with the given ID
+func RoomTopic(roomID string) string {
+	return roomTopicPrefix + roomID
+}
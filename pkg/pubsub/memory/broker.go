@@ -0,0 +1,81 @@
+// Package memory is a message broker running in-process. It stands in for Kafka or Pulsar when testing code that
+// publishes and consumes messages.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+)
+
+// queueSize is the number of messages a consumer can be behind before publishing to its topic blocks
+const queueSize = 1024
+
+// Broker passes messages from publishers to consumers. Every consumer of a topic receives the messages published to
+// the topic after the consumer was created. A Broker is safe for concurrent use.
+type Broker struct {
+	mutex     sync.Mutex
+	consumers map[string][]*consumer
+}
+
+// NewPublisher returns a Publisher publishing to the given topic
+func (b *Broker) NewPublisher(topic string) pubsub.Publisher {
+	return &publisher{
+		broker: b,
+		topic:  topic,
+	}
+}
+
+// NewConsumer returns a Consumer of the given topic. Like the other consumers, it passes messages on to subscriber
+// until ctx is canceled.
+func (b *Broker) NewConsumer(ctx context.Context, topic string, subscriber chan string) pubsub.Consumer {
+	c := &consumer{
+		broker:     b,
+		ctx:        ctx,
+		topic:      topic,
+		subscriber: subscriber,
+		queue:      make(chan string, queueSize),
+		closed:     make(chan struct{}),
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.consumers[topic] = append(b.consumers[topic], c)
+
+	return c
+}
+
+func (b *Broker) publish(topic string, msg string) {
+	b.mutex.Lock()
+	consumers := append([]*consumer(nil), b.consumers[topic]...)
+	b.mutex.Unlock()
+
+	for _, c := range consumers {
+		select {
+		case c.queue <- msg:
+		case <-c.closed:
+		}
+	}
+}
+
+func (b *Broker) remove(c *consumer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	consumers := b.consumers[c.topic]
+	for i, other := range consumers {
+		if other == c {
+			b.consumers[c.topic] = append(consumers[:i], consumers[i+1:]...)
+			return
+		}
+	}
+}
+
+// NewBroker returns a new Broker without any consumers
+func NewBroker() *Broker {
+	return &Broker{
+		consumers: make(map[string][]*consumer),
+	}
+}
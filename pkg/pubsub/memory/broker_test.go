@@ -0,0 +1,78 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/memory"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/pubsubtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumer(t *testing.T) {
+	pubsubtest.TestConsumer(t, func(ctx context.Context, t *testing.T, subscriber chan string) (pubsub.Publisher, pubsub.Consumer) {
+		broker := memory.NewBroker()
+		consumer := broker.NewConsumer(ctx, "zombie", subscriber)
+
+		return broker.NewPublisher("zombie"), consumer
+	})
+}
+
+func TestBroker(t *testing.T) {
+	t.Run("Should pass messages to every consumer of the topic", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broker := memory.NewBroker()
+
+		first := broker.NewConsumer(ctx, "zombie", make(chan string, 1))
+		second := broker.NewConsumer(ctx, "zombie", make(chan string, 1))
+		other := broker.NewConsumer(ctx, "other", make(chan string, 1))
+
+		for _, c := range []pubsub.Consumer{first, second, other} {
+			c := c
+			go func() { _ = c.ListenForMessages() }()
+		}
+
+		// When
+		require.NoError(t, broker.NewPublisher("zombie").SendMsg("hi"))
+
+		// Then
+		assert.Equal(t, "hi", <-first.SubscriberChannel())
+		assert.Equal(t, "hi", <-second.SubscriberChannel())
+		assert.Empty(t, other.SubscriberChannel())
+	})
+
+	t.Run("Should stop passing messages to closed consumers", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+
+		broker := memory.NewBroker()
+		consumer := broker.NewConsumer(ctx, "zombie", make(chan string))
+
+		// When
+		require.NoError(t, consumer.Close())
+
+		// Then
+		for i := 0; i < 2000; i++ {
+			require.NoError(t, broker.NewPublisher("zombie").SendMsg("hi"))
+		}
+	})
+
+	t.Run("Should stop listening when the context is canceled", func(t *testing.T) {
+		// Given
+		ctx, cancelFn := context.WithCancel(context.Background())
+		consumer := memory.NewBroker().NewConsumer(ctx, "zombie", make(chan string))
+
+		// When
+		cancelFn()
+
+		// Then
+		assert.NoError(t, consumer.ListenForMessages())
+	})
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type consumer struct {
+	broker     *Broker
+	ctx        context.Context
+	topic      string
+	subscriber chan string
+	// queue contains the messages published to the topic that haven't been passed on to subscriber yet
+	queue     chan string
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// ListenForMessages passes messages on to the subscriber channel. It blocks until the context is canceled.
+func (c *consumer) ListenForMessages() error {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case msg := <-c.queue:
+			select {
+			case c.subscriber <- msg:
+			case <-c.ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func (c *consumer) SubscriberChannel() chan string {
+	return c.subscriber
+}
+
+func (c *consumer) Close() error {
+	c.closeOnce.Do(func() {
+		c.broker.remove(c)
+		close(c.closed)
+	})
+
+	return nil
+}
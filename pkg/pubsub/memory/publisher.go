@@ -0,0 +1,17 @@
+package memory
+
+type publisher struct {
+	broker *Broker
+	topic  string
+}
+
+// SendMsg passes the message on to the consumers of the topic. It blocks while a consumer's queue is full.
+func (p *publisher) SendMsg(msg string) error {
+	p.broker.publish(p.topic, msg)
+
+	return nil
+}
+
+func (p *publisher) Close() error {
+	return nil
+}
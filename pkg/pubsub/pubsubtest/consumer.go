@@ -0,0 +1,159 @@
+// Package pubsubtest contains tests that every pubsub.Consumer must pass
+package pubsubtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/pubsub"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	timeout = 30 * time.Second
+	// messageCount is the number of messages to send through the consumer. It's well above the batch sizes consumers
+	// are likely to read in.
+	messageCount = 100
+	// probePrefix is the prefix of messages sent to find out when the consumer is ready
+	probePrefix = "probe-"
+)
+
+// NewPubSub returns a Publisher and a Consumer of a topic no other test uses. The Consumer must pass messages on to
+// subscriber until ctx is canceled. The Publisher and Consumer are closed by the test.
+type NewPubSub func(ctx context.Context, t *testing.T, subscriber chan string) (pubsub.Publisher, pubsub.Consumer)
+
+// TestConsumer tests that consumers made by newPubSub follow the pubsub.Consumer contract
+func TestConsumer(t *testing.T, newPubSub NewPubSub) {
+	t.Run("Should pass on every message, in order, until the context is canceled", func(t *testing.T) {
+		testPassesOnMessages(t, newPubSub)
+	})
+
+	t.Run("Should keep messages until the subscriber is ready for them", func(t *testing.T) {
+		testKeepsMessages(t, newPubSub)
+	})
+
+	t.Run("Should stop listening when the context is canceled while a message waits for the subscriber", func(t *testing.T) {
+		testStopsWhileMessageWaits(t, newPubSub)
+	})
+}
+
+func testPassesOnMessages(t *testing.T, newPubSub NewPubSub) {
+	// Given
+	ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+	defer cancelFn()
+
+	publisher, consumer, stopped := start(ctx, t, newPubSub)
+
+	// When
+	for i := 0; i < messageCount; i++ {
+		require.NoError(t, publisher.SendMsg(fmt.Sprintf("msg-%d", i)))
+	}
+
+	// Then
+	for i := 0; i < messageCount; i++ {
+		assert.Equal(t, fmt.Sprintf("msg-%d", i), Receive(ctx, t, consumer))
+	}
+
+	cancelFn()
+	WaitForStop(t, stopped)
+}
+
+func testKeepsMessages(t *testing.T, newPubSub NewPubSub) {
+	// Given
+	ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+	defer cancelFn()
+
+	publisher, consumer, stopped := start(ctx, t, newPubSub)
+
+	for i := 0; i < messageCount; i++ {
+		require.NoError(t, publisher.SendMsg(fmt.Sprintf("msg-%d", i)))
+	}
+
+	// When
+	time.Sleep(100 * time.Millisecond)
+
+	// Then
+	for i := 0; i < messageCount; i++ {
+		assert.Equal(t, fmt.Sprintf("msg-%d", i), Receive(ctx, t, consumer))
+	}
+
+	cancelFn()
+	WaitForStop(t, stopped)
+}
+
+func testStopsWhileMessageWaits(t *testing.T, newPubSub NewPubSub) {
+	// Given
+	ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+	defer cancelFn()
+
+	publisher, _, stopped := start(ctx, t, newPubSub)
+	require.NoError(t, publisher.SendMsg("unread"))
+
+	// When
+	cancelFn()
+
+	// Then
+	WaitForStop(t, stopped)
+}
+
+// start creates a publisher and a consumer, and starts listening, see Listen
+func start(ctx context.Context, t *testing.T, newPubSub NewPubSub) (pubsub.Publisher, pubsub.Consumer, chan error) {
+	publisher, consumer := newPubSub(ctx, t, make(chan string))
+
+	t.Cleanup(func() {
+		assert.NoError(t, consumer.Close())
+		assert.NoError(t, publisher.Close())
+	})
+
+	return publisher, consumer, Listen(ctx, t, publisher, consumer)
+}
+
+// Listen starts the consumer listening, and returns when it passes on messages published by the publisher. Until then,
+// probe messages are published, which Receive skips. The returned channel gets the error from ListenForMessages.
+func Listen(ctx context.Context, t *testing.T, publisher pubsub.Publisher, consumer pubsub.Consumer) chan error {
+	stopped := make(chan error, 1)
+
+	go func() { stopped <- consumer.ListenForMessages() }()
+
+	// Consumers of real brokers may start at the newest message, so messages sent before they are ready are missed
+	for i := 0; ; i++ {
+		require.NoError(t, publisher.SendMsg(fmt.Sprintf("%s%d", probePrefix, i)))
+
+		select {
+		case <-consumer.SubscriberChannel():
+			return stopped
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			require.FailNow(t, "timed out waiting for the consumer to be ready")
+		}
+	}
+}
+
+// Receive returns the next message from the consumer, skipping probes published by Listen
+func Receive(ctx context.Context, t *testing.T, consumer pubsub.Consumer) string {
+	for {
+		select {
+		case msg := <-consumer.SubscriberChannel():
+			if !strings.HasPrefix(msg, probePrefix) {
+				return msg
+			}
+		case <-ctx.Done():
+			require.FailNow(t, "timed out waiting for message")
+		}
+	}
+}
+
+// WaitForStop waits for a consumer started by Listen to stop listening
+func WaitForStop(t *testing.T, stopped chan error) {
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(timeout):
+		require.FailNow(t, "consumer didn't stop listening")
+	}
+}
@@ -0,0 +1,292 @@
+package room
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"go.uber.org/zap"
+)
+
+// validID matches the room IDs clients can use
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`) //nolint:gochecknoglobals
+
+// NewConfig returns the game config for a new room. It's called for every room that is created, so each room can get
+// its own map.
+type NewConfig func(roomID string) (gamelogic.Config, error)
+
+// Manager creates rooms when clients join them, and removes them when the last client leaves. Clients that don't ask
+// for a specific room are matched with other players, see Join. A Manager is safe for concurrent use.
+type Manager struct {
+	mutex             sync.Mutex
+	ctx               context.Context
+	log               *zap.SugaredLogger
+	lobbyConfig       lobby.Config
+	broadcasterConfig broadcast.Config
+	newConfig         NewConfig
+	rooms             map[string]*Room
+	// queue contains the rooms in the order they were created, which is the order the matchmaker fills them in
+	queue []*Room
+	// lastMatchID is the number in the ID of the room last created by the matchmaker
+	lastMatchID uint64
+	// clientRooms are the rooms of each client, by client ID
+	clientRooms map[string]*Room
+}
+
+// Join adds a client to a room, creating the room if it doesn't exist. The room's game starts when its lobby says so.
+// If the room ID is empty, the client joins the oldest room that is open, or a new room if none are. It returns the
+// messages that bring the client up to date, see gamelogic.GameLogic.AddPlayer. subscribe is called with the room's
+// broadcaster, and must subscribe the client to it.
+func (m *Manager) Join(roomID string, clientID string, subscribe func(*broadcast.Broadcaster)) ([]*protocol.Envelope, error) {
+	if roomID != "" && !validID.MatchString(roomID) {
+		return nil, fmt.Errorf("invalid room ID %q, must match %s", roomID, validID)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.clientRooms[clientID]; ok {
+		return nil, fmt.Errorf("client %s is already in a room", clientID)
+	}
+
+	r, err := m.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.lobby.Full() {
+		return nil, fmt.Errorf("room %s is full", r.ID)
+	}
+
+	messages, err := r.logic.AddPlayer(clientID, func() { subscribe(r.broadcaster) })
+	if err != nil {
+		m.removeIfEmpty(r)
+		return nil, fmt.Errorf("joining room %s: %w", r.ID, err)
+	}
+
+	r.clients[clientID] = true
+	m.clientRooms[clientID] = r
+
+	// Joined after subscribing, so the client receives the lobby state published when it joins
+	r.lobby.Join(clientID)
+
+	return messages, nil
+}
+
+// findRoom returns the room with the given ID, creating it if it doesn't exist. If the room ID is empty, it returns
+// the oldest open room, or a new room if none are open. The caller must hold the mutex.
+func (m *Manager) findRoom(roomID string) (*Room, error) {
+	if roomID == "" {
+		for _, r := range m.queue {
+			if r.lobby.Open() {
+				return r, nil
+			}
+		}
+
+		roomID = m.newMatchID()
+	}
+
+	if r, ok := m.rooms[roomID]; ok {
+		return r, nil
+	}
+
+	r, err := m.createRoom(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("creating room %s: %w", roomID, err)
+	}
+
+	return r, nil
+}
+
+// newMatchID returns an unused ID for a room created by the matchmaker. The caller must hold the mutex.
+func (m *Manager) newMatchID() string {
+	for {
+		m.lastMatchID++
+
+		id := fmt.Sprintf("match-%d", m.lastMatchID)
+		if _, ok := m.rooms[id]; !ok {
+			return id
+		}
+	}
+}
+
+// Leave removes a client from its room. The room is removed when its last client leaves.
+func (m *Manager) Leave(clientID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	r, ok := m.clientRooms[clientID]
+	if !ok {
+		return nil
+	}
+
+	delete(m.clientRooms, clientID)
+	delete(r.clients, clientID)
+
+	r.lobby.Leave(clientID)
+	err := r.logic.RemovePlayer(clientID)
+
+	m.removeIfEmpty(r)
+
+	if err != nil {
+		return fmt.Errorf("leaving room %s: %w", r.ID, err)
+	}
+
+	return nil
+}
+
+// Dispatch passes a message from a client on to the handlers of the client's room
+func (m *Manager) Dispatch(clientID string, e *protocol.Envelope) error {
+	m.mutex.Lock()
+	r, ok := m.clientRooms[clientID]
+	m.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("client %s is not in a room", clientID)
+	}
+
+	return r.dispatcher.Dispatch(clientID, e)
+}
+
+// Room returns the room with the given ID, if it exists
+func (m *Manager) Room(roomID string) (*Room, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	r, ok := m.rooms[roomID]
+
+	return r, ok
+}
+
+// Rooms describes the rooms that exist, sorted by ID
+func (m *Manager) Rooms() []Info {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	rooms := make([]Info, 0, len(m.rooms))
+	for _, r := range m.rooms {
+		rooms = append(rooms, r.info())
+	}
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].ID < rooms[j].ID
+	})
+
+	return rooms
+}
+
+// OpenRooms describes the rooms whose games haven't started and aren't full, in the order the matchmaker fills them
+func (m *Manager) OpenRooms() []Info {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	rooms := make([]Info, 0)
+
+	for _, r := range m.queue {
+		if r.lobby.Open() {
+			rooms = append(rooms, r.info())
+		}
+	}
+
+	return rooms
+}
+
+// createRoom creates a room, and starts its game when the room's lobby says so. The caller must hold the mutex.
+func (m *Manager) createRoom(roomID string) (*Room, error) {
+	config, err := m.newConfig(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("creating game config: %w", err)
+	}
+
+	ctx, cancelFn := context.WithCancel(m.ctx)
+	logger := m.log.With("room", roomID)
+	broadcaster := broadcast.NewWithConfig(logger, m.broadcasterConfig)
+
+	logic, err := gamelogic.NewGameLogic(ctx, logger, broadcaster, config)
+	if err != nil {
+		cancelFn()
+		return nil, fmt.Errorf("creating game logic: %w", err)
+	}
+
+	roomLobby := lobby.NewLobby(m.lobbyConfig, func(state *lobby.StateMessage) {
+		err := broadcaster.Publish(broadcast.TopicLobby, logic.NewEnvelope(protocol.TypeLobbyState, state))
+		if err != nil {
+			logger.Errorf("publishing lobby state: %s", err.Error())
+		}
+	})
+
+	dispatcher := protocol.NewDispatcher()
+	logic.RegisterHandlers(dispatcher)
+	roomLobby.RegisterHandlers(dispatcher)
+
+	r := &Room{
+		ID:          roomID,
+		logic:       logic,
+		lobby:       roomLobby,
+		settings:    newSettings(roomLobby, config, logic),
+		broadcaster: broadcaster,
+		dispatcher:  dispatcher,
+		cancelFn:    cancelFn,
+		clients:     make(map[string]bool),
+	}
+
+	m.rooms[roomID] = r
+	m.queue = append(m.queue, r)
+
+	go func() {
+		logger.Info("Room created, waiting for players to get ready")
+
+		if !roomLobby.Wait(ctx) {
+			logger.Info("Room removed before its game started")
+			return
+		}
+
+		logger.Info("Running game")
+		logic.Run()
+		logger.Info("Room game stopped")
+	}()
+
+	return r, nil
+}
+
+// removeIfEmpty stops the game of a room without clients, and removes the room. The caller must hold the mutex.
+func (m *Manager) removeIfEmpty(r *Room) {
+	if len(r.clients) > 0 {
+		return
+	}
+
+	r.cancelFn()
+	delete(m.rooms, r.ID)
+
+	for i, queued := range m.queue {
+		if queued == r {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			break
+		}
+	}
+}
+
+// NewManager returns a new Manager without any rooms. The games in the rooms are stopped when ctx is canceled.
+func NewManager(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	lobbyConfig lobby.Config,
+	broadcasterConfig broadcast.Config,
+	newConfig NewConfig,
+) *Manager {
+	return &Manager{
+		ctx:               ctx,
+		log:               logger,
+		lobbyConfig:       lobbyConfig,
+		broadcasterConfig: broadcasterConfig,
+		newConfig:         newConfig,
+		rooms:             make(map[string]*Room),
+		clientRooms:       make(map[string]*Room),
+	}
+}
@@ -0,0 +1,212 @@
+package room_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/player"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+	"github.com/yngvark/gr-zombie/pkg/room"
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestManager(t *testing.T) {
+	t.Run("Should run a separate game in each room", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		var broadcasters []*broadcast.Broadcaster
+
+		subscribe := func(b *broadcast.Broadcaster) { broadcasters = append(broadcasters, b) }
+
+		// When
+		_, err := rooms.Join("abc", "1", subscribe)
+		require.NoError(t, err)
+
+		_, err = rooms.Join("abc", "2", subscribe)
+		require.NoError(t, err)
+
+		_, err = rooms.Join("xyz", "3", subscribe)
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, []room.Info{info("abc", 2), info("xyz", 1)}, rooms.Rooms())
+
+		abc, ok := rooms.Room("abc")
+		require.True(t, ok)
+
+		xyz, ok := rooms.Room("xyz")
+		require.True(t, ok)
+
+		assert.NotSame(t, abc.Logic(), xyz.Logic())
+		assert.NotSame(t, abc.Logic().WorldMap(), xyz.Logic().WorldMap())
+
+		require.Len(t, broadcasters, 3)
+		assert.Same(t, broadcasters[0], broadcasters[1])
+		assert.NotSame(t, broadcasters[0], broadcasters[2])
+	})
+
+	t.Run("Should remove rooms when the last client leaves", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		_, err := rooms.Join("abc", "1", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		_, err = rooms.Join("abc", "2", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		// When
+		require.NoError(t, rooms.Leave("1"))
+		assert.Len(t, rooms.Rooms(), 1)
+
+		require.NoError(t, rooms.Leave("2"))
+
+		// Then
+		assert.Empty(t, rooms.Rooms())
+
+		_, ok := rooms.Room("abc")
+		assert.False(t, ok)
+	})
+
+	t.Run("Should dispatch messages to the game in the client's room", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		_, err := rooms.Join("abc", "1", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		// Then
+		assert.NoError(t, rooms.Dispatch("1", protocol.NewEnvelope(protocol.TypePlayerMove, 0, &player.MoveCommand{DX: 1})))
+		assert.NoError(t, rooms.Dispatch("1", protocol.NewEnvelope(protocol.TypeLobbyReady, 0, &lobby.ReadyCommand{Ready: false})))
+		assert.Error(t, rooms.Dispatch("1", protocol.NewEnvelope(protocol.TypePlayerMove, 0, &player.MoveCommand{DX: 2})))
+		assert.Error(t, rooms.Dispatch("2", protocol.NewEnvelope(protocol.TypePlayerMove, 0, &player.MoveCommand{DX: 1})))
+	})
+
+	t.Run("Should reject invalid room IDs and clients joining twice", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		_, err := rooms.Join("abc", "1", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		// Then
+		_, err = rooms.Join("abc", "1", func(*broadcast.Broadcaster) {})
+		assert.Error(t, err)
+
+		_, err = rooms.Join("not/a room", "2", func(*broadcast.Broadcaster) {})
+		assert.Error(t, err)
+		assert.Len(t, rooms.Rooms(), 1)
+	})
+
+	t.Run("Should match clients without a room into the oldest open room", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		// When
+		for _, clientID := range []string{"1", "2", "3"} {
+			_, err := rooms.Join("", clientID, func(*broadcast.Broadcaster) {})
+			require.NoError(t, err)
+		}
+
+		// Then
+		assert.Equal(t, []room.Info{info("match-1", roomSize), info("match-2", 1)}, rooms.Rooms())
+		assert.Equal(t, []room.Info{info("match-2", 1)}, rooms.OpenRooms())
+
+		// When
+		require.NoError(t, rooms.Leave("1"))
+
+		_, err := rooms.Join("", "4", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, []room.Info{info("match-1", roomSize), info("match-2", 1)}, rooms.Rooms())
+	})
+
+	t.Run("Should reject clients joining a full room", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		for _, clientID := range []string{"1", "2"} {
+			_, err := rooms.Join("abc", clientID, func(*broadcast.Broadcaster) {})
+			require.NoError(t, err)
+		}
+
+		// When
+		_, err := rooms.Join("abc", "3", func(*broadcast.Broadcaster) {})
+
+		// Then
+		assert.Error(t, err)
+		assert.Equal(t, []room.Info{info("abc", roomSize)}, rooms.Rooms())
+	})
+
+	t.Run("Should start the game when every player in the room is ready", func(t *testing.T) {
+		// Given
+		rooms, cancelFn := newManager()
+		defer cancelFn()
+
+		_, err := rooms.Join("", "1", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		r, ok := rooms.Room("match-1")
+		require.True(t, ok)
+
+		// When
+		require.NoError(t, rooms.Dispatch("1", protocol.NewEnvelope(protocol.TypeLobbyReady, 0, &lobby.ReadyCommand{Ready: true})))
+
+		// Then
+		assert.Eventually(t, r.Lobby().Started, time.Second, time.Millisecond)
+		assert.Empty(t, rooms.OpenRooms())
+
+		// When
+		_, err = rooms.Join("", "2", func(*broadcast.Broadcaster) {})
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, []room.Info{info("match-2", 1)}, rooms.OpenRooms())
+	})
+}
+
+// roomSize is the most players in the rooms of the Manager returned by newManager
+const roomSize = 2
+
+// info returns the Info of a room with the settings used by newManager, whose game hasn't started
+func info(roomID string, clients int) room.Info {
+	return room.Info{
+		ID:      roomID,
+		Clients: clients,
+		Settings: room.Settings{
+			Size:         roomSize,
+			ZombieCount:  1,
+			TickInterval: time.Second,
+			MapWidth:     20,
+			MapHeight:    10,
+		},
+	}
+}
+
+func newManager() (*room.Manager, context.CancelFunc) {
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	lobbyConfig := lobby.Config{Size: roomSize}
+
+	rooms := room.NewManager(ctx, zap.NewNop().Sugar(), lobbyConfig, broadcast.DefaultConfig(), func(string) (gamelogic.Config, error) {
+		return gamelogic.DefaultConfig(), nil
+	})
+
+	return rooms, cancelFn
+}
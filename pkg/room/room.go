@@ -0,0 +1,81 @@
+// Package room runs several games at once, each in its own room, so that groups of players don't interfere with each
+// other
+package room
+
+import (
+	"context"
+	"time"
+
+	"github.com/yngvark/gr-zombie/pkg/gamelogic"
+	"github.com/yngvark/gr-zombie/pkg/lobby"
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"github.com/yngvark/gr-zombie/pkg/pubsub/broadcast"
+)
+
+// Room is a game with its own map, zombies, players and broadcaster. The game starts when the room's lobby says so.
+type Room struct {
+	ID          string
+	logic       *gamelogic.GameLogic
+	lobby       *lobby.Lobby
+	settings    Settings
+	broadcaster *broadcast.Broadcaster
+	dispatcher  *protocol.Dispatcher
+	cancelFn    context.CancelFunc
+	// clients are the IDs of the clients in the room
+	clients map[string]bool
+}
+
+// Logic returns the room's game
+func (r *Room) Logic() *gamelogic.GameLogic {
+	return r.logic
+}
+
+// Lobby returns the room's lobby
+func (r *Room) Lobby() *lobby.Lobby {
+	return r.lobby
+}
+
+// info describes the room. The caller must hold the Manager's mutex.
+func (r *Room) info() Info {
+	return Info{
+		ID:       r.ID,
+		Clients:  len(r.clients),
+		Started:  r.lobby.Started(),
+		Settings: r.settings,
+	}
+}
+
+// Info describes a room
+type Info struct {
+	ID       string   `json:"id"`
+	Clients  int      `json:"clients"`
+	Started  bool     `json:"started"`
+	Settings Settings `json:"settings"`
+}
+
+// Settings are the settings of the game in a room, which players may want to know before joining it
+type Settings struct {
+	// Size is the most players the game can have, or 0 if there is no limit
+	Size         int           `json:"size"`
+	Countdown    time.Duration `json:"countdown"`
+	ZombieCount  int           `json:"zombieCount"`
+	TickInterval time.Duration `json:"tickInterval"`
+	MapWidth     int           `json:"mapWidth"`
+	MapHeight    int           `json:"mapHeight"`
+}
+
+func newSettings(l *lobby.Lobby, config gamelogic.Config, logic *gamelogic.GameLogic) Settings {
+	settings := Settings{
+		Size:         l.Size(),
+		Countdown:    l.Countdown(),
+		ZombieCount:  config.ZombieCount,
+		TickInterval: config.TickInterval,
+	}
+
+	if tiles := logic.WorldMap().Tiles; len(tiles) > 0 {
+		settings.MapWidth = len(tiles[0])
+		settings.MapHeight = len(tiles)
+	}
+
+	return settings
+}
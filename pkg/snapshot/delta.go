@@ -0,0 +1,48 @@
+package snapshot
+
+// Delta is what changed from one snapshot to another. Clients apply it to their copy of the snapshot at BaseTick to get
+// the snapshot at the tick of the message. Clients must therefore keep every snapshot from the last one they
+// acknowledged, until the server sends a delta with a newer base.
+type Delta struct {
+	// Keyframe tells that the delta contains the whole snapshot, and doesn't depend on any earlier snapshot
+	Keyframe bool `json:"keyframe,omitempty"`
+	// BaseTick is the tick of the snapshot the delta is relative to. It is 0 for keyframes.
+	BaseTick uint64 `json:"baseTick,omitempty"`
+	// Entities are the entities that were added or changed
+	Entities []Entity `json:"entities,omitempty"`
+	// Removed are the entities that were removed
+	Removed []Key `json:"removed,omitempty"`
+}
+
+// Diff returns what changed from the base snapshot to the current one
+func Diff(base *Snapshot, current *Snapshot) *Delta {
+	d := &Delta{BaseTick: base.Tick}
+
+	// Both snapshots are sorted, so they can be compared by walking through them side by side
+	b, c := base.entities, current.entities
+
+	for len(b) > 0 || len(c) > 0 {
+		switch {
+		case len(b) == 0 || len(c) > 0 && c[0].Key.less(b[0].Key):
+			d.Entities = append(d.Entities, c[0])
+			c = c[1:]
+		case len(c) == 0 || b[0].Key.less(c[0].Key):
+			d.Removed = append(d.Removed, b[0].Key)
+			b = b[1:]
+		default:
+			if b[0] != c[0] {
+				d.Entities = append(d.Entities, c[0])
+			}
+
+			b, c = b[1:], c[1:]
+		}
+	}
+
+	return d
+}
+
+// Ack is sent by clients to acknowledge that they have the snapshot of a tick, so that the server can send later
+// snapshots as deltas from it
+type Ack struct {
+	Tick uint64 `json:"tick"`
+}
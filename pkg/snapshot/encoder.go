@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"sync"
+)
+
+// maxUnacknowledged is the number of snapshots sent to a client that are remembered while waiting for an
+// acknowledgement. Acknowledgements of older snapshots are ignored.
+const maxUnacknowledged = 256
+
+// Encoder turns snapshots into deltas for one client, based on what the client has acknowledged. An Encoder is safe for
+// concurrent use, so that acknowledgements can be received while sending snapshots.
+type Encoder struct {
+	mutex sync.Mutex
+	// sent are the snapshots sent to the client after the acknowledged one, oldest first
+	sent []*Snapshot
+	// acknowledged is the newest snapshot the client has acknowledged, if any
+	acknowledged *Snapshot
+}
+
+// Delta returns the delta to send to the client for a snapshot. It is a keyframe if the snapshot is, or if the client
+// hasn't acknowledged any snapshot yet.
+func (e *Encoder) Delta(s *Snapshot) *Delta {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.sent) == maxUnacknowledged {
+		copy(e.sent, e.sent[1:])
+		e.sent = e.sent[:len(e.sent)-1]
+	}
+
+	e.sent = append(e.sent, s)
+
+	if s.Keyframe || e.acknowledged == nil {
+		return s.Full()
+	}
+
+	return Diff(e.acknowledged, s)
+}
+
+// Ack records that the client has the snapshot of a tick. Unknown ticks, for instance ones older than the last
+// acknowledged tick, are ignored.
+func (e *Encoder) Ack(tick uint64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i, s := range e.sent {
+		if s.Tick == tick {
+			e.acknowledged = s
+			e.sent = append(e.sent[:0], e.sent[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// NewEncoder returns a new Encoder for a client that hasn't received any snapshots
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
@@ -0,0 +1,123 @@
+package snapshot
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf field numbers, see protocol.proto
+const (
+	protoKeyKindField protowire.Number = 1
+	protoKeyIDField   protowire.Number = 2
+
+	protoEntityKeyField   protowire.Number = 1
+	protoEntityXField     protowire.Number = 2
+	protoEntityYField     protowire.Number = 3
+	protoEntityStateField protowire.Number = 4
+
+	protoDeltaKeyframeField protowire.Number = 1
+	protoDeltaBaseTickField protowire.Number = 2
+	protoDeltaEntitiesField protowire.Number = 3
+	protoDeltaRemovedField  protowire.Number = 4
+
+	protoAckTickField protowire.Number = 1
+)
+
+func (k *Key) appendProto(b []byte) []byte {
+	b = protocol.AppendProtoString(b, protoKeyKindField, k.Kind)
+
+	return protocol.AppendProtoString(b, protoKeyIDField, k.ID)
+}
+
+func (k *Key) unmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoKeyKindField:
+			k.Kind = f.String()
+		case protoKeyIDField:
+			k.ID = f.String()
+		}
+
+		return nil
+	})
+}
+
+func (e *Entity) appendProto(b []byte) []byte {
+	b = protocol.AppendProtoMessage(b, protoEntityKeyField, e.Key.appendProto)
+	b = protocol.AppendProtoInt(b, protoEntityXField, e.X)
+	b = protocol.AppendProtoInt(b, protoEntityYField, e.Y)
+
+	return protocol.AppendProtoString(b, protoEntityStateField, e.State)
+}
+
+func (e *Entity) unmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoEntityKeyField:
+			return e.Key.unmarshalProto(f.Bytes())
+		case protoEntityXField:
+			e.X = f.Int()
+		case protoEntityYField:
+			e.Y = f.Int()
+		case protoEntityStateField:
+			e.State = f.String()
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (d *Delta) AppendProto(b []byte) []byte {
+	if d.Keyframe {
+		b = protocol.AppendProtoUint(b, protoDeltaKeyframeField, 1)
+	}
+
+	b = protocol.AppendProtoUint(b, protoDeltaBaseTickField, d.BaseTick)
+
+	for i := range d.Entities {
+		b = protocol.AppendProtoMessage(b, protoDeltaEntitiesField, d.Entities[i].appendProto)
+	}
+
+	for i := range d.Removed {
+		b = protocol.AppendProtoMessage(b, protoDeltaRemovedField, d.Removed[i].appendProto)
+	}
+
+	return b
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (d *Delta) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoDeltaKeyframeField:
+			d.Keyframe = f.Uint() != 0
+		case protoDeltaBaseTickField:
+			d.BaseTick = f.Uint()
+		case protoDeltaEntitiesField:
+			d.Entities = append(d.Entities, Entity{})
+			return d.Entities[len(d.Entities)-1].unmarshalProto(f.Bytes())
+		case protoDeltaRemovedField:
+			d.Removed = append(d.Removed, Key{})
+			return d.Removed[len(d.Removed)-1].unmarshalProto(f.Bytes())
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (a *Ack) AppendProto(b []byte) []byte {
+	return protocol.AppendProtoUint(b, protoAckTickField, a.Tick)
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (a *Ack) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number == protoAckTickField {
+			a.Tick = f.Uint()
+		}
+
+		return nil
+	})
+}
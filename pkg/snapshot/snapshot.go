@@ -0,0 +1,75 @@
+// Package snapshot knows the state of the world at each tick, and how to send clients only what changed since the last
+// state they acknowledged
+package snapshot
+
+import (
+	"sort"
+)
+
+// Entity kinds
+const (
+	KindZombie = "zombie"
+	KindPlayer = "player"
+)
+
+// Key identifies an entity. IDs are only unique within a kind.
+type Key struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+}
+
+// Entity is the state of something in the world
+type Entity struct {
+	Key
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	State string `json:"state,omitempty"`
+}
+
+// Snapshot is the state of all entities at a tick. Snapshots are shared between clients, so they must not be changed
+// once created.
+type Snapshot struct {
+	Tick uint64
+	// Keyframe tells that every client should be sent the whole snapshot, so that clients that have missed something
+	// get back in sync
+	Keyframe bool
+	// entities are sorted by kind and ID
+	entities []Entity
+}
+
+// Entities returns the entities in the snapshot, sorted by kind and ID
+func (s *Snapshot) Entities() []Entity {
+	return s.entities
+}
+
+// Full returns a Delta containing the whole snapshot
+func (s *Snapshot) Full() *Delta {
+	return &Delta{
+		Keyframe: true,
+		Entities: s.entities,
+	}
+}
+
+// New returns a new Snapshot of the given entities
+func New(tick uint64, keyframe bool, entities []Entity) *Snapshot {
+	sorted := make([]Entity, len(entities))
+	copy(sorted, entities)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key.less(sorted[j].Key)
+	})
+
+	return &Snapshot{
+		Tick:     tick,
+		Keyframe: keyframe,
+		entities: sorted,
+	}
+}
+
+func (k Key) less(other Key) bool {
+	if k.Kind != other.Kind {
+		return k.Kind < other.Kind
+	}
+
+	return k.ID < other.ID
+}
@@ -0,0 +1,109 @@
+package snapshot_test
+
+import (
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/snapshot"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func zombie(id string, x int, y int) snapshot.Entity {
+	return snapshot.Entity{Key: snapshot.Key{Kind: snapshot.KindZombie, ID: id}, X: x, Y: y}
+}
+
+func player(id string, x int, y int) snapshot.Entity {
+	return snapshot.Entity{Key: snapshot.Key{Kind: snapshot.KindPlayer, ID: id}, X: x, Y: y}
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("Should contain added, changed and removed entities", func(t *testing.T) {
+		// Given
+		base := snapshot.New(1, false, []snapshot.Entity{zombie("1", 0, 0), zombie("2", 0, 0), zombie("3", 0, 0), player("1", 5, 5)})
+
+		chasing := zombie("3", 0, 0)
+		chasing.State = "chasing"
+
+		current := snapshot.New(2, false, []snapshot.Entity{chasing, player("2", 1, 1), zombie("1", 1, 0), player("1", 5, 5)})
+
+		// When
+		delta := snapshot.Diff(base, current)
+
+		// Then
+		assert.Equal(t, &snapshot.Delta{
+			BaseTick: 1,
+			Entities: []snapshot.Entity{player("2", 1, 1), zombie("1", 1, 0), chasing},
+			Removed:  []snapshot.Key{{Kind: snapshot.KindZombie, ID: "2"}},
+		}, delta)
+	})
+
+	t.Run("Should be empty when nothing changed", func(t *testing.T) {
+		entities := []snapshot.Entity{zombie("1", 0, 0), player("1", 5, 5)}
+
+		delta := snapshot.Diff(snapshot.New(1, false, entities), snapshot.New(2, false, entities))
+
+		assert.Equal(t, &snapshot.Delta{BaseTick: 1}, delta)
+	})
+}
+
+//nolint:funlen
+func TestEncoder(t *testing.T) {
+	t.Run("Should send keyframes until the client acknowledges a snapshot", func(t *testing.T) {
+		// Given
+		encoder := snapshot.NewEncoder()
+
+		// When
+		first := encoder.Delta(snapshot.New(1, false, []snapshot.Entity{zombie("1", 0, 0)}))
+		second := encoder.Delta(snapshot.New(2, false, []snapshot.Entity{zombie("1", 1, 0)}))
+
+		// Then
+		assert.Equal(t, &snapshot.Delta{Keyframe: true, Entities: []snapshot.Entity{zombie("1", 0, 0)}}, first)
+		assert.Equal(t, &snapshot.Delta{Keyframe: true, Entities: []snapshot.Entity{zombie("1", 1, 0)}}, second)
+	})
+
+	t.Run("Should send deltas from the last acknowledged snapshot", func(t *testing.T) {
+		// Given
+		encoder := snapshot.NewEncoder()
+
+		encoder.Delta(snapshot.New(1, false, []snapshot.Entity{zombie("1", 0, 0), zombie("2", 0, 0)}))
+		encoder.Delta(snapshot.New(2, false, []snapshot.Entity{zombie("1", 1, 0), zombie("2", 0, 0)}))
+		encoder.Ack(1)
+
+		// When
+		delta := encoder.Delta(snapshot.New(3, false, []snapshot.Entity{zombie("1", 2, 0), zombie("2", 0, 0)}))
+
+		// Then
+		assert.Equal(t, &snapshot.Delta{BaseTick: 1, Entities: []snapshot.Entity{zombie("1", 2, 0)}}, delta)
+	})
+
+	t.Run("Should ignore acknowledgements of older or unknown snapshots", func(t *testing.T) {
+		// Given
+		encoder := snapshot.NewEncoder()
+
+		encoder.Delta(snapshot.New(1, false, []snapshot.Entity{zombie("1", 0, 0)}))
+		encoder.Delta(snapshot.New(2, false, []snapshot.Entity{zombie("1", 1, 0)}))
+		encoder.Ack(2)
+
+		// When
+		encoder.Ack(1)
+		encoder.Ack(7)
+
+		// Then
+		delta := encoder.Delta(snapshot.New(3, false, []snapshot.Entity{zombie("1", 1, 0)}))
+		assert.Equal(t, &snapshot.Delta{BaseTick: 2}, delta)
+	})
+
+	t.Run("Should send keyframes whole, even when the client has acknowledged a snapshot", func(t *testing.T) {
+		// Given
+		encoder := snapshot.NewEncoder()
+
+		encoder.Delta(snapshot.New(1, false, []snapshot.Entity{zombie("1", 0, 0)}))
+		encoder.Ack(1)
+
+		// When
+		delta := encoder.Delta(snapshot.New(2, true, []snapshot.Entity{zombie("1", 0, 0)}))
+
+		// Then
+		assert.Equal(t, &snapshot.Delta{Keyframe: true, Entities: []snapshot.Entity{zombie("1", 0, 0)}}, delta)
+	})
+}
@@ -0,0 +1,74 @@
+package worldmap
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// GenerateFunc generates the tiles of a map, using rnd for all randomness
+type GenerateFunc func(width int, height int, rnd *rand.Rand) [][]TileKind
+
+// generators contains the available map generators by name
+var generators = map[string]GenerateFunc{ //nolint:gochecknoglobals
+	"empty":   generateEmpty,
+	"caves":   generateCaves,
+	"dungeon": generateDungeon,
+}
+
+const minGeneratedSize = 3
+
+// Generate generates a map with the named generator. The same name, size and seed always produce the same map, and the
+// walkable part of the map is always connected, meaning every walkable tile can be reached from every other.
+func Generate(name string, width int, height int, seed int64) (*WorldMap, error) {
+	generate, ok := generators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown map generator %s, valid generators are: %v", name, GeneratorNames())
+	}
+
+	if width < minGeneratedSize || height < minGeneratedSize {
+		return nil, fmt.Errorf("map must be at least %dx%d, was %dx%d", minGeneratedSize, minGeneratedSize, width, height)
+	}
+
+	tiles := generate(width, height, rand.New(rand.NewSource(seed))) //nolint:gosec
+
+	m, err := newFromTiles(tiles)
+	if err != nil {
+		return nil, err
+	}
+
+	m.keepLargestWalkableRegion()
+
+	return m, nil
+}
+
+// GeneratorNames returns the names of the available map generators
+func GeneratorNames() []string {
+	names := make([]string, 0, len(generators))
+
+	for name := range generators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func generateEmpty(width int, height int, _ *rand.Rand) [][]TileKind {
+	return generateTiles(width, height)
+}
+
+func filledTiles(width int, height int, kind TileKind) [][]TileKind {
+	tiles := make([][]TileKind, height)
+
+	for y := range tiles {
+		tiles[y] = make([]TileKind, width)
+
+		for x := range tiles[y] {
+			tiles[y][x] = kind
+		}
+	}
+
+	return tiles
+}
@@ -0,0 +1,67 @@
+package worldmap
+
+import "math/rand"
+
+const (
+	caveInitialWallChance = 0.45
+	caveSmoothingSteps    = 5
+	// When smoothing, a wall stays a wall if it has at least caveWallSurvival wall neighbours (out of 8), and a floor
+	// becomes a wall if it has at least caveWallBirth wall neighbours
+	caveWallSurvival = 4
+	caveWallBirth    = 5
+)
+
+// generateCaves generates cave-like maps with cellular automata: tiles start out as random walls and floors, and are
+// then smoothed by repeatedly turning tiles into walls if most of their neighbours are walls.
+func generateCaves(width int, height int, rnd *rand.Rand) [][]TileKind {
+	tiles := filledTiles(width, height, Wall)
+
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			if rnd.Float64() >= caveInitialWallChance {
+				tiles[y][x] = Floor
+			}
+		}
+	}
+
+	for i := 0; i < caveSmoothingSteps; i++ {
+		tiles = smoothCaves(tiles)
+	}
+
+	return tiles
+}
+
+func smoothCaves(tiles [][]TileKind) [][]TileKind {
+	height := len(tiles)
+	width := len(tiles[0])
+	smoothed := filledTiles(width, height, Wall)
+
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			threshold := caveWallBirth
+			if tiles[y][x] == Wall {
+				threshold = caveWallSurvival
+			}
+
+			if countWallNeighbours(tiles, x, y) < threshold {
+				smoothed[y][x] = Floor
+			}
+		}
+	}
+
+	return smoothed
+}
+
+func countWallNeighbours(tiles [][]TileKind, x int, y int) int {
+	count := 0
+
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if (dx != 0 || dy != 0) && tiles[y+dy][x+dx] == Wall {
+				count++
+			}
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,161 @@
+package worldmap
+
+import "math/rand"
+
+const (
+	dungeonRoomAttempts = 50
+	dungeonMinRoomSize  = 3
+	dungeonMaxRoomSize  = 10
+)
+
+type room struct {
+	x, y, width, height int
+}
+
+func (r room) center() (int, int) {
+	return r.x + r.width/2, r.y + r.height/2 //nolint:gomnd
+}
+
+// overlaps returns whether the rooms overlap or touch, as rooms need at least one wall between them
+func (r room) overlaps(other room) bool {
+	return r.x <= other.x+other.width && other.x <= r.x+r.width &&
+		r.y <= other.y+other.height && other.y <= r.y+r.height
+}
+
+// generateDungeon generates dungeon-like maps of rectangular rooms. Each room is connected to the previous one with an
+// L-shaped corridor, with doors where corridors enter rooms.
+func generateDungeon(width int, height int, rnd *rand.Rand) [][]TileKind {
+	d := &dungeon{
+		tiles:  filledTiles(width, height, Wall),
+		inRoom: make([][]bool, height),
+	}
+
+	for y := range d.inRoom {
+		d.inRoom[y] = make([]bool, width)
+	}
+
+	rooms := make([]room, 0)
+
+	for i := 0; i < dungeonRoomAttempts; i++ {
+		r, ok := randomRoom(width, height, rnd)
+		if !ok || overlapsAny(r, rooms) {
+			continue
+		}
+
+		d.carveRoom(r)
+
+		if len(rooms) > 0 {
+			d.carveCorridor(rooms[len(rooms)-1], r, rnd)
+		}
+
+		rooms = append(rooms, r)
+	}
+
+	if len(rooms) == 0 {
+		// The map is too small for rooms, so make it a single room
+		d.carveRoom(room{x: 1, y: 1, width: width - 2, height: height - 2}) //nolint:gomnd
+	}
+
+	return d.tiles
+}
+
+type dungeon struct {
+	tiles [][]TileKind
+	// inRoom tells which tiles are room floor, as opposed to corridor floor
+	inRoom [][]bool
+}
+
+func randomRoom(width int, height int, rnd *rand.Rand) (room, bool) {
+	// Leave room for the outer wall
+	maxWidth := min(dungeonMaxRoomSize, width-2)   //nolint:gomnd
+	maxHeight := min(dungeonMaxRoomSize, height-2) //nolint:gomnd
+
+	if maxWidth < dungeonMinRoomSize || maxHeight < dungeonMinRoomSize {
+		return room{}, false
+	}
+
+	r := room{
+		width:  dungeonMinRoomSize + rnd.Intn(maxWidth-dungeonMinRoomSize+1),
+		height: dungeonMinRoomSize + rnd.Intn(maxHeight-dungeonMinRoomSize+1),
+	}
+
+	r.x = 1 + rnd.Intn(width-r.width-1)
+	r.y = 1 + rnd.Intn(height-r.height-1)
+
+	return r, true
+}
+
+func overlapsAny(r room, rooms []room) bool {
+	for _, other := range rooms {
+		if r.overlaps(other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *dungeon) carveRoom(r room) {
+	for y := r.y; y < r.y+r.height; y++ {
+		for x := r.x; x < r.x+r.width; x++ {
+			d.tiles[y][x] = Floor
+			d.inRoom[y][x] = true
+		}
+	}
+}
+
+func (d *dungeon) carveCorridor(from room, to room, rnd *rand.Rand) {
+	fromX, fromY := from.center()
+	toX, toY := to.center()
+
+	if rnd.Intn(2) == 0 { //nolint:gomnd
+		d.carveHorizontal(fromX, toX, fromY)
+		d.carveVertical(fromY, toY, toX)
+	} else {
+		d.carveVertical(fromY, toY, fromX)
+		d.carveHorizontal(fromX, toX, toY)
+	}
+}
+
+func (d *dungeon) carveHorizontal(x1 int, x2 int, y int) {
+	for x := min(x1, x2); x <= max(x1, x2); x++ {
+		d.carveCorridorTile(x, y, 1, 0)
+	}
+}
+
+func (d *dungeon) carveVertical(y1 int, y2 int, x int) {
+	for y := min(y1, y2); y <= max(y1, y2); y++ {
+		d.carveCorridorTile(x, y, 0, 1)
+	}
+}
+
+// carveCorridorTile turns a wall into corridor floor. (dx, dy) is the direction of the corridor. Where the corridor
+// breaks through a room's wall, meaning there's room floor straight ahead or behind, a door is placed.
+func (d *dungeon) carveCorridorTile(x int, y int, dx int, dy int) {
+	if d.tiles[y][x] != Wall {
+		return
+	}
+
+	if d.inRoom[y+dy][x+dx] || d.inRoom[y-dy][x-dx] {
+		d.tiles[y][x] = Door
+		return
+	}
+
+	d.tiles[y][x] = Floor
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
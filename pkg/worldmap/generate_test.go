@@ -0,0 +1,98 @@
+package worldmap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, name := range worldmap.GeneratorNames() {
+		name := name
+
+		t.Run(fmt.Sprintf("Should generate the same %s map for the same seed", name), func(t *testing.T) {
+			m, err := worldmap.Generate(name, 60, 40, 45)
+			require.NoError(t, err)
+
+			sameSeedMap, err := worldmap.Generate(name, 60, 40, 45)
+			require.NoError(t, err)
+
+			assert.Equal(t, m, sameSeedMap)
+		})
+
+		t.Run(fmt.Sprintf("Should generate connected %s maps", name), func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				m, err := worldmap.Generate(name, 60, 40, seed)
+				require.NoError(t, err)
+
+				assert.True(t, m.IsConnected(), "map with seed %d is not connected", seed)
+				assert.True(t, hasWalkableTile(m), "map with seed %d has no walkable tiles", seed)
+			}
+		})
+	}
+
+	for _, name := range []string{"caves", "dungeon"} {
+		name := name
+
+		t.Run(fmt.Sprintf("Should generate different %s maps for different seeds", name), func(t *testing.T) {
+			m, err := worldmap.Generate(name, 60, 40, 1)
+			require.NoError(t, err)
+
+			otherMap, err := worldmap.Generate(name, 60, 40, 2)
+			require.NoError(t, err)
+
+			assert.NotEqual(t, m.Tiles, otherMap.Tiles)
+		})
+
+		t.Run(fmt.Sprintf("Should surround %s maps with walls", name), func(t *testing.T) {
+			m, err := worldmap.Generate(name, 60, 40, 45)
+			require.NoError(t, err)
+
+			for x := 0; x < 60; x++ {
+				assert.False(t, m.IsWalkable(x, 0))
+				assert.False(t, m.IsWalkable(x, 39))
+			}
+
+			for y := 0; y < 40; y++ {
+				assert.False(t, m.IsWalkable(0, y))
+				assert.False(t, m.IsWalkable(59, y))
+			}
+		})
+	}
+
+	t.Run("Should generate dungeons with doors", func(t *testing.T) {
+		m, err := worldmap.Generate("dungeon", 60, 40, 45)
+		require.NoError(t, err)
+
+		assert.Contains(t, tileKinds(m), worldmap.Door)
+	})
+
+	t.Run("Should fail on unknown generator", func(t *testing.T) {
+		_, err := worldmap.Generate("maze", 60, 40, 45)
+		assert.Error(t, err)
+	})
+
+	t.Run("Should fail on too small maps", func(t *testing.T) {
+		_, err := worldmap.Generate("caves", 2, 40, 45)
+		assert.Error(t, err)
+	})
+}
+
+func hasWalkableTile(m *worldmap.WorldMap) bool {
+	_, _, ok := m.NearestWalkable(0, 0)
+	return ok
+}
+
+func tileKinds(m *worldmap.WorldMap) []worldmap.TileKind {
+	kinds := make([]worldmap.TileKind, 0)
+
+	for _, row := range m.Tiles {
+		kinds = append(kinds, row...)
+	}
+
+	return kinds
+}
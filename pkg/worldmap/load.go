@@ -0,0 +1,97 @@
+package worldmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Load loads a WorldMap from a file. The format is decided by the file extension: ".json" for maps exported by the
+// Tiled map editor (see ParseTiled), and ".txt" for ASCII grids (see ParseASCII).
+func Load(path string) (*WorldMap, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("opening map file: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseTiled(f)
+	case ".txt":
+		return ParseASCII(f)
+	default:
+		return nil, fmt.Errorf("unknown map file format: %s", path)
+	}
+}
+
+// asciiTiles maps characters in ASCII grids to tile kinds
+var asciiTiles = map[rune]TileKind{ //nolint:gochecknoglobals
+	'.': Floor,
+	'#': Wall,
+	'~': Water,
+	'+': Door,
+}
+
+// ParseASCII parses a map from an ASCII grid, where each line is a row of tiles:
+//
+//	#####
+//	#..~#
+//	#.+.#
+//	#####
+//
+// '.' is floor, '#' is wall, '~' is water and '+' is door. Empty lines are ignored.
+func ParseASCII(r io.Reader) (*WorldMap, error) {
+	tiles := make([][]TileKind, 0)
+	scanner := bufio.NewScanner(r)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			continue
+		}
+
+		row := make([]TileKind, 0, len(line))
+
+		for _, c := range line {
+			kind, ok := asciiTiles[c]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown tile character '%c'", lineNumber, c)
+			}
+
+			row = append(row, kind)
+		}
+
+		if len(tiles) > 0 && len(row) != len(tiles[0]) {
+			return nil, fmt.Errorf("line %d: expected %d tiles, got %d", lineNumber, len(tiles[0]), len(row))
+		}
+
+		tiles = append(tiles, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading map: %w", err)
+	}
+
+	return newFromTiles(tiles)
+}
+
+func newFromTiles(tiles [][]TileKind) (*WorldMap, error) {
+	if len(tiles) == 0 || len(tiles[0]) == 0 {
+		return nil, fmt.Errorf("map has no tiles")
+	}
+
+	return &WorldMap{
+		MinX:  0,
+		MaxX:  len(tiles[0]),
+		MinY:  0,
+		MaxY:  len(tiles),
+		Tiles: tiles,
+	}, nil
+}
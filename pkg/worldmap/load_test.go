@@ -0,0 +1,79 @@
+package worldmap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	f, w, o, d := worldmap.Floor, worldmap.Wall, worldmap.Water, worldmap.Door
+
+	expectedRoom := &worldmap.WorldMap{
+		MaxX: 5,
+		MaxY: 4,
+		Tiles: [][]worldmap.TileKind{
+			{w, w, w, w, w},
+			{w, f, f, o, w},
+			{w, f, d, f, w},
+			{w, w, w, w, w},
+		},
+	}
+
+	t.Run("Should load ASCII grid", func(t *testing.T) {
+		m, err := worldmap.Load("testdata/room.txt")
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedRoom, m)
+	})
+
+	t.Run("Should load Tiled JSON map", func(t *testing.T) {
+		m, err := worldmap.Load("testdata/room.json")
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedRoom, m)
+	})
+
+	t.Run("Should fail on unknown file format", func(t *testing.T) {
+		_, err := worldmap.Load("testdata/room.tmx")
+		assert.Error(t, err)
+	})
+
+	t.Run("Should fail on unknown ASCII tile", func(t *testing.T) {
+		_, err := worldmap.ParseASCII(strings.NewReader("#.#\n#X#\n"))
+		assert.EqualError(t, err, "line 2: unknown tile character 'X'")
+	})
+
+	t.Run("Should fail on ragged ASCII grid", func(t *testing.T) {
+		_, err := worldmap.ParseASCII(strings.NewReader("###\n##\n"))
+		assert.EqualError(t, err, "line 2: expected 3 tiles, got 2")
+	})
+
+	t.Run("Should fail on empty ASCII grid", func(t *testing.T) {
+		_, err := worldmap.ParseASCII(strings.NewReader("\n\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Should fail on Tiled map with external tileset", func(t *testing.T) {
+		_, err := worldmap.ParseTiled(strings.NewReader(
+			`{"width":1,"height":1,"layers":[],"tilesets":[{"firstgid":1,"source":"dungeon.tsx"}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Should fail on Tiled map without positive size", func(t *testing.T) {
+		for _, size := range []string{`"width":-1,"height":1`, `"width":1,"height":-1`, `"width":0,"height":0`} {
+			_, err := worldmap.ParseTiled(strings.NewReader(`{` + size + `,"layers":[],"tilesets":[]}`))
+			assert.Error(t, err, size)
+		}
+	})
+
+	t.Run("Should fail on Tiled map with unknown tile kind", func(t *testing.T) {
+		_, err := worldmap.ParseTiled(strings.NewReader(
+			`{"width":1,"height":1,"layers":[],"tilesets":[{"firstgid":1,"tiles":[{"id":0,"type":"lava"}]}]}`))
+		assert.Error(t, err)
+	})
+}
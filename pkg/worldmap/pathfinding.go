@@ -0,0 +1,291 @@
+package worldmap
+
+// Point is a position on a WorldMap
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Adjacency decides which tiles are neighbours when finding paths
+type Adjacency int
+
+const (
+	// FourWay makes tiles neighbours of the tiles above, below, left and right of them
+	FourWay Adjacency = 4
+	// EightWay makes tiles neighbours of the tiles around them, including diagonally. Diagonal moves past a corner of a
+	// tile that isn't walkable are not allowed.
+	EightWay Adjacency = 8
+)
+
+// Costs of moving to a neighbouring tile are scaled, so that diagonal moves can cost approximately sqrt(2) times as much
+// as straight moves while still using integers
+const (
+	straightStepCost = 10
+	diagonalStepCost = 14
+)
+
+//nolint:gochecknoglobals
+var (
+	straightDirections = []Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonalDirections = []Point{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+// Pathfinder finds shortest paths on a WorldMap with the A* algorithm. The cost of a path is the sum of the movement
+// costs of the tiles entered. A Pathfinder reuses memory between searches, so it is not safe for concurrent use; create
+// one per goroutine instead.
+type Pathfinder struct {
+	m         *WorldMap
+	adjacency Adjacency
+	width     int
+	height    int
+
+	// movementCosts holds the movement cost of each tile, by tile index, or 0 if the tile isn't walkable
+	movementCosts []int
+
+	// Search state, indexed by tile index. A tile's state is only valid if its generation equals the current search's
+	// generation, which saves us from clearing everything between searches.
+	generation []uint32
+	costs      []int
+	parents    []int
+	closed     []bool
+	current    uint32
+	open       openSet
+}
+
+// FindPath returns the cheapest path from one point to another, including both. It returns false if there is no path,
+// for instance because one of the points isn't walkable.
+func (p *Pathfinder) FindPath(from Point, to Point) ([]Point, bool) {
+	if !p.isWalkable(from) || !p.isWalkable(to) {
+		return nil, false
+	}
+
+	p.startSearch()
+
+	start, goal := p.index(from), p.index(to)
+	p.visit(start, 0, -1)
+	p.open.push(openNode{index: start, priority: p.heuristic(from, to)})
+
+	for len(p.open) > 0 {
+		node := p.open.pop()
+		if p.closed[node.index] {
+			continue
+		}
+
+		if node.index == goal {
+			return p.path(goal), true
+		}
+
+		p.closed[node.index] = true
+		p.expand(node.index, to)
+	}
+
+	return nil, false
+}
+
+func (p *Pathfinder) expand(index int, to Point) {
+	current := p.point(index)
+
+	p.expandDirections(index, current, to, straightDirections, straightStepCost)
+
+	if p.adjacency == EightWay {
+		p.expandDirections(index, current, to, diagonalDirections, diagonalStepCost)
+	}
+}
+
+func (p *Pathfinder) expandDirections(index int, current Point, to Point, directions []Point, stepCost int) {
+	for _, d := range directions {
+		next := Point{current.X + d.X, current.Y + d.Y}
+
+		if !p.isWalkable(next) {
+			continue
+		}
+
+		if d.X != 0 && d.Y != 0 && (!p.isWalkable(Point{current.X + d.X, current.Y}) ||
+			!p.isWalkable(Point{current.X, current.Y + d.Y})) {
+			// Don't cut corners
+			continue
+		}
+
+		nextIndex := p.index(next)
+		cost := p.costs[index] + stepCost*p.movementCosts[nextIndex]
+
+		if p.isVisited(nextIndex) && (p.closed[nextIndex] || cost >= p.costs[nextIndex]) {
+			continue
+		}
+
+		p.visit(nextIndex, cost, index)
+		p.open.push(openNode{index: nextIndex, priority: cost + p.heuristic(next, to), cost: cost})
+	}
+}
+
+// heuristic estimates the cost from one point to another. It never overestimates, since the lowest movement cost of any
+// tile is 1, which makes A* find the cheapest path.
+func (p *Pathfinder) heuristic(from Point, to Point) int {
+	dx, dy := abs(from.X-to.X), abs(from.Y-to.Y)
+
+	if p.adjacency == EightWay {
+		return straightStepCost*(dx+dy) + (diagonalStepCost-2*straightStepCost)*min(dx, dy) //nolint:gomnd
+	}
+
+	return straightStepCost * (dx + dy)
+}
+
+func (p *Pathfinder) path(goal int) []Point {
+	path := make([]Point, 0)
+
+	for index := goal; index != -1; index = p.parents[index] {
+		path = append(path, p.point(index))
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+func (p *Pathfinder) startSearch() {
+	p.current++
+	p.open = p.open[:0]
+
+	if p.current == 0 {
+		// The generation counter wrapped around, so old states could be mistaken for current ones
+		for i := range p.generation {
+			p.generation[i] = 0
+		}
+
+		p.current = 1
+	}
+}
+
+func (p *Pathfinder) visit(index int, cost int, parent int) {
+	if !p.isVisited(index) {
+		p.generation[index] = p.current
+		p.closed[index] = false
+	}
+
+	p.costs[index] = cost
+	p.parents[index] = parent
+}
+
+func (p *Pathfinder) isVisited(index int) bool {
+	return p.generation[index] == p.current
+}
+
+func (p *Pathfinder) isWalkable(point Point) bool {
+	x, y := point.X-p.m.MinX, point.Y-p.m.MinY
+	if x < 0 || x >= p.width || y < 0 || y >= p.height {
+		return false
+	}
+
+	return p.movementCosts[y*p.width+x] > 0
+}
+
+func (p *Pathfinder) index(point Point) int {
+	return (point.Y-p.m.MinY)*p.width + point.X - p.m.MinX
+}
+
+func (p *Pathfinder) point(index int) Point {
+	return Point{X: p.m.MinX + index%p.width, Y: p.m.MinY + index/p.width}
+}
+
+// NewPathfinder returns a new Pathfinder for the map. The map's tiles must not change while the Pathfinder is in use.
+func NewPathfinder(m *WorldMap, adjacency Adjacency) *Pathfinder {
+	width, height := m.width(), len(m.Tiles)
+	size := width * height
+
+	movementCosts := make([]int, size)
+
+	for y, row := range m.Tiles {
+		for x, kind := range row {
+			if kind.IsWalkable() {
+				movementCosts[y*width+x] = kind.MovementCost()
+			}
+		}
+	}
+
+	return &Pathfinder{
+		m:             m,
+		adjacency:     adjacency,
+		width:         width,
+		height:        height,
+		movementCosts: movementCosts,
+		generation:    make([]uint32, size),
+		costs:         make([]int, size),
+		parents:       make([]int, size),
+		closed:        make([]bool, size),
+	}
+}
+
+type openNode struct {
+	index    int
+	priority int
+	cost     int
+}
+
+// openSet is a binary heap of nodes to explore, ordered by priority. Nodes can occur more than once; stale entries are
+// skipped when popped. It doesn't use container/heap, since boxing every node in an interface{} makes searches on large
+// maps noticeably slower.
+type openSet []openNode
+
+// less orders nodes by priority. Ties are broken in favour of the node that has come furthest, which is likely closer to
+// the goal, so that fewer nodes are explored on open maps.
+func (s openSet) less(i, j int) bool {
+	if s[i].priority == s[j].priority {
+		return s[i].cost > s[j].cost
+	}
+
+	return s[i].priority < s[j].priority
+}
+
+func (s *openSet) push(node openNode) {
+	*s = append(*s, node)
+	h := *s
+
+	for i := len(h) - 1; i > 0; {
+		parent := (i - 1) / 2 //nolint:gomnd
+		if !h.less(i, parent) {
+			break
+		}
+
+		h[i], h[parent] = h[parent], h[i]
+		i = parent
+	}
+}
+
+func (s *openSet) pop() openNode {
+	h := *s
+	node := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+
+	for i := 0; ; {
+		smallest := i
+
+		for _, child := range []int{2*i + 1, 2*i + 2} { //nolint:gomnd
+			if child < len(h) && h.less(child, smallest) {
+				smallest = child
+			}
+		}
+
+		if smallest == i {
+			break
+		}
+
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+
+	*s = h
+
+	return node
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
@@ -0,0 +1,164 @@
+package worldmap_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestFindPath(t *testing.T) {
+	t.Run("Should find a straight path", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.....
+.....`)
+		pathfinder := worldmap.NewPathfinder(m, worldmap.FourWay)
+
+		// When
+		path, ok := pathfinder.FindPath(worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 3, Y: 0})
+
+		// Then
+		require.True(t, ok)
+		assert.Equal(t, []worldmap.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}}, path)
+	})
+
+	t.Run("Should return only the start when going nowhere", func(t *testing.T) {
+		m := parseMap(t, `...`)
+
+		path, ok := worldmap.NewPathfinder(m, worldmap.FourWay).FindPath(worldmap.Point{X: 1, Y: 0}, worldmap.Point{X: 1, Y: 0})
+
+		require.True(t, ok)
+		assert.Equal(t, []worldmap.Point{{1, 0}}, path)
+	})
+
+	t.Run("Should go around walls", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.#.
+.#.
+...`)
+		pathfinder := worldmap.NewPathfinder(m, worldmap.FourWay)
+
+		// When
+		path, ok := pathfinder.FindPath(worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 2, Y: 0})
+
+		// Then
+		require.True(t, ok)
+		assert.Equal(t, []worldmap.Point{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}}, path)
+	})
+
+	t.Run("Should prefer a longer path when the shorter one is more expensive", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.~~~.
+.....`)
+		pathfinder := worldmap.NewPathfinder(m, worldmap.FourWay)
+
+		// When
+		path, ok := pathfinder.FindPath(worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 4, Y: 0})
+
+		// Then
+		require.True(t, ok)
+		assert.Equal(t, []worldmap.Point{{0, 0}, {0, 1}, {1, 1}, {2, 1}, {3, 1}, {4, 1}, {4, 0}}, path)
+	})
+
+	t.Run("Should move diagonally with eight-way adjacency", func(t *testing.T) {
+		m := parseMap(t, `
+...
+...
+...`)
+
+		path, ok := worldmap.NewPathfinder(m, worldmap.EightWay).FindPath(worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 2, Y: 2})
+
+		require.True(t, ok)
+		assert.Equal(t, []worldmap.Point{{0, 0}, {1, 1}, {2, 2}}, path)
+	})
+
+	t.Run("Should not cut corners of walls", func(t *testing.T) {
+		m := parseMap(t, `
+.#
+..`)
+
+		path, ok := worldmap.NewPathfinder(m, worldmap.EightWay).FindPath(worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 1, Y: 1})
+
+		require.True(t, ok)
+		assert.Equal(t, []worldmap.Point{{0, 0}, {0, 1}, {1, 1}}, path)
+	})
+
+	t.Run("Should not find a path to unreachable or unwalkable tiles", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.#.
+.#.
+.##`)
+		pathfinder := worldmap.NewPathfinder(m, worldmap.EightWay)
+
+		// Then
+		for _, to := range []worldmap.Point{{2, 0}, {1, 0}, {5, 5}} {
+			_, ok := pathfinder.FindPath(worldmap.Point{X: 0, Y: 0}, to)
+			assert.False(t, ok, "found path to %v", to)
+		}
+	})
+
+	t.Run("Should find the same paths when reused", func(t *testing.T) {
+		// Given
+		m, err := worldmap.Generate("caves", 60, 40, 45)
+		require.NoError(t, err)
+
+		from, to := walkableCorners(m)
+		pathfinder := worldmap.NewPathfinder(m, worldmap.EightWay)
+
+		expected, ok := pathfinder.FindPath(from, to)
+		require.True(t, ok)
+
+		// When
+		_, _ = pathfinder.FindPath(to, from)
+		path, ok := pathfinder.FindPath(from, to)
+
+		// Then
+		require.True(t, ok)
+		assert.Equal(t, expected, path)
+	})
+}
+
+func BenchmarkFindPath(b *testing.B) {
+	for _, name := range []string{"empty", "caves", "dungeon"} {
+		m, err := worldmap.Generate(name, 256, 256, 45)
+		require.NoError(b, err)
+
+		from, to := walkableCorners(m)
+
+		for _, adjacency := range []worldmap.Adjacency{worldmap.FourWay, worldmap.EightWay} {
+			pathfinder := worldmap.NewPathfinder(m, adjacency)
+
+			b.Run(fmt.Sprintf("%s/%d-way", name, adjacency), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, ok := pathfinder.FindPath(from, to); !ok {
+						b.Fatal("no path found")
+					}
+				}
+			})
+		}
+	}
+}
+
+func parseMap(t *testing.T, ascii string) *worldmap.WorldMap {
+	m, err := worldmap.ParseASCII(strings.NewReader(strings.TrimPrefix(ascii, "\n")))
+	require.NoError(t, err)
+
+	return m
+}
+
+// walkableCorners returns the walkable tiles nearest the top left and bottom right corners of the map
+func walkableCorners(m *worldmap.WorldMap) (worldmap.Point, worldmap.Point) {
+	fromX, fromY, _ := m.NearestWalkable(m.MinX, m.MinY)
+	toX, toY, _ := m.NearestWalkable(m.MaxX, m.MaxY)
+
+	return worldmap.Point{X: fromX, Y: fromY}, worldmap.Point{X: toX, Y: toY}
+}
@@ -0,0 +1,94 @@
+package worldmap
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf field numbers, see protocol.proto
+const (
+	protoMinXField protowire.Number = iota + 1
+	protoMaxXField
+	protoMinYField
+	protoMaxYField
+	protoTilesField
+)
+
+// protoRowTilesField is the field number of the tiles in a row
+const protoRowTilesField protowire.Number = 1
+
+// AppendProto implements protocol.ProtoPayload
+func (m *WorldMap) AppendProto(b []byte) []byte {
+	b = protocol.AppendProtoInt(b, protoMinXField, m.MinX)
+	b = protocol.AppendProtoInt(b, protoMaxXField, m.MaxX)
+	b = protocol.AppendProtoInt(b, protoMinYField, m.MinY)
+	b = protocol.AppendProtoInt(b, protoMaxYField, m.MaxY)
+
+	for _, row := range m.Tiles {
+		row := row
+
+		b = protocol.AppendProtoMessage(b, protoTilesField, func(b []byte) []byte {
+			return protocol.AppendProtoMessage(b, protoRowTilesField, func(b []byte) []byte {
+				// Packed, as is the default for repeated scalars in proto3
+				for _, kind := range row {
+					b = protowire.AppendVarint(b, uint64(kind))
+				}
+
+				return b
+			})
+		})
+	}
+
+	return b
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (m *WorldMap) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoMinXField:
+			m.MinX = f.Int()
+		case protoMaxXField:
+			m.MaxX = f.Int()
+		case protoMinYField:
+			m.MinY = f.Int()
+		case protoMaxYField:
+			m.MaxY = f.Int()
+		case protoTilesField:
+			row, err := unmarshalProtoRow(f.Bytes())
+			if err != nil {
+				return err
+			}
+
+			m.Tiles = append(m.Tiles, row)
+		}
+
+		return nil
+	})
+}
+
+func unmarshalProtoRow(b []byte) ([]TileKind, error) {
+	var kinds []uint64
+
+	err := protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number != protoRowTilesField {
+			return nil
+		}
+
+		var err error
+
+		kinds, err = f.Uints(kinds)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]TileKind, len(kinds))
+	for i, kind := range kinds {
+		row[i] = TileKind(kind)
+	}
+
+	return row, nil
+}
@@ -0,0 +1,70 @@
+package worldmap
+
+// walkableRegions returns the regions of connected walkable tiles, each as a list of positions. Tiles are connected to
+// their horizontal and vertical neighbours.
+func (m *WorldMap) walkableRegions() [][][2]int {
+	visited := make(map[[2]int]bool)
+	regions := make([][][2]int, 0)
+
+	for row := range m.Tiles {
+		for column := range m.Tiles[row] {
+			start := [2]int{m.MinX + column, m.MinY + row}
+			if visited[start] || !m.IsWalkable(start[0], start[1]) {
+				continue
+			}
+
+			regions = append(regions, m.floodFill(start, visited))
+		}
+	}
+
+	return regions
+}
+
+func (m *WorldMap) floodFill(start [2]int, visited map[[2]int]bool) [][2]int {
+	region := make([][2]int, 0)
+	stack := [][2]int{start}
+	visited[start] = true
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		region = append(region, p)
+
+		for _, n := range [][2]int{{p[0] + 1, p[1]}, {p[0] - 1, p[1]}, {p[0], p[1] + 1}, {p[0], p[1] - 1}} {
+			if !visited[n] && m.IsWalkable(n[0], n[1]) {
+				visited[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+
+	return region
+}
+
+// keepLargestWalkableRegion turns every walkable tile that isn't part of the largest walkable region into wall, so that
+// every walkable tile can be reached from every other
+func (m *WorldMap) keepLargestWalkableRegion() {
+	regions := m.walkableRegions()
+
+	largest := 0
+	for i, region := range regions {
+		if len(region) > len(regions[largest]) {
+			largest = i
+		}
+	}
+
+	for i, region := range regions {
+		if i == largest {
+			continue
+		}
+
+		for _, p := range region {
+			m.Tiles[p[1]-m.MinY][p[0]-m.MinX] = Wall
+		}
+	}
+}
+
+// IsConnected returns whether every walkable tile can be reached from every other walkable tile
+func (m *WorldMap) IsConnected() bool {
+	return len(m.walkableRegions()) <= 1
+}
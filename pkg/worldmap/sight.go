@@ -0,0 +1,58 @@
+package worldmap
+
+// HasLineOfSight returns whether one position can be seen from another, which is the case when no tile on the straight
+// line between them blocks sight. The tiles at the two positions themselves don't block sight, but positions outside
+// the map can't be seen.
+func (m *WorldMap) HasLineOfSight(from Point, to Point) bool {
+	if _, ok := m.TileAt(from.X, from.Y); !ok {
+		return false
+	}
+
+	if _, ok := m.TileAt(to.X, to.Y); !ok {
+		return false
+	}
+
+	// Bresenham's line algorithm
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	stepX, stepY := sign(to.X-from.X), sign(to.Y-from.Y)
+	x, y := from.X, from.Y
+	err := dx + dy
+
+	for {
+		if x == to.X && y == to.Y {
+			return true
+		}
+
+		if (x != from.X || y != from.Y) && m.blocksSight(x, y) {
+			return false
+		}
+
+		e2 := 2 * err //nolint:gomnd
+		if e2 >= dy {
+			err += dy
+			x += stepX
+		}
+
+		if e2 <= dx {
+			err += dx
+			y += stepY
+		}
+	}
+}
+
+func (m *WorldMap) blocksSight(x int, y int) bool {
+	kind, ok := m.TileAt(x, y)
+
+	return !ok || kind.BlocksSight()
+}
+
+func sign(a int) int {
+	switch {
+	case a < 0:
+		return -1
+	case a > 0:
+		return 1
+	default:
+		return 0
+	}
+}
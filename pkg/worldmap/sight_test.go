@@ -0,0 +1,41 @@
+package worldmap_test
+
+import (
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasLineOfSight(t *testing.T) {
+	m := parseMap(t, `
+.....
+..#..
+.~+..
+.....`)
+
+	testCases := []struct {
+		name     string
+		from     worldmap.Point
+		to       worldmap.Point
+		expected bool
+	}{
+		{name: "Should see along a row", from: worldmap.Point{X: 0, Y: 0}, to: worldmap.Point{X: 4, Y: 0}, expected: true},
+		{name: "Should see itself", from: worldmap.Point{X: 1, Y: 1}, to: worldmap.Point{X: 1, Y: 1}, expected: true},
+		{name: "Should not see through walls", from: worldmap.Point{X: 2, Y: 0}, to: worldmap.Point{X: 2, Y: 3}, expected: false},
+		{name: "Should not see diagonally through walls", from: worldmap.Point{X: 1, Y: 0}, to: worldmap.Point{X: 3, Y: 2}, expected: false},
+		{name: "Should see through water and doors", from: worldmap.Point{X: 0, Y: 2}, to: worldmap.Point{X: 4, Y: 2}, expected: true},
+		{name: "Should see walls", from: worldmap.Point{X: 2, Y: 3}, to: worldmap.Point{X: 2, Y: 1}, expected: true},
+		{name: "Should see the same both ways", from: worldmap.Point{X: 3, Y: 2}, to: worldmap.Point{X: 1, Y: 0}, expected: false},
+		{name: "Should not see outside the map", from: worldmap.Point{X: 0, Y: 0}, to: worldmap.Point{X: 9, Y: 0}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, m.HasLineOfSight(tc.from, tc.to))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package worldmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TileKind is a kind of tile, like floor or wall
+type TileKind int
+
+// Tile kinds
+const (
+	Floor TileKind = iota
+	Wall
+	Water
+	Door
+)
+
+// TileProperties contains the properties of a TileKind
+type TileProperties struct {
+	Name     string
+	Walkable bool
+	// MovementCost is the cost of entering a tile of this kind. It is only relevant for walkable tiles.
+	MovementCost int
+	// BlocksSight is whether tiles of this kind can't be seen through
+	BlocksSight bool
+}
+
+var tileProperties = map[TileKind]TileProperties{ //nolint:gochecknoglobals
+	Floor: {Name: "floor", Walkable: true, MovementCost: 1},
+	Wall:  {Name: "wall", Walkable: false, BlocksSight: true},
+	Water: {Name: "water", Walkable: true, MovementCost: 3}, //nolint:gomnd
+	Door:  {Name: "door", Walkable: true, MovementCost: 2},  //nolint:gomnd
+}
+
+// Properties returns the TileProperties of the TileKind
+func (k TileKind) Properties() TileProperties {
+	return tileProperties[k]
+}
+
+// IsWalkable returns whether tiles of this kind can be walked on
+func (k TileKind) IsWalkable() bool {
+	return k.Properties().Walkable
+}
+
+// MovementCost returns the cost of entering a tile of this kind
+func (k TileKind) MovementCost() int {
+	return k.Properties().MovementCost
+}
+
+// BlocksSight returns whether tiles of this kind can't be seen through
+func (k TileKind) BlocksSight() bool {
+	return k.Properties().BlocksSight
+}
+
+func (k TileKind) String() string {
+	properties, ok := tileProperties[k]
+	if !ok {
+		return fmt.Sprintf("TileKind(%d)", int(k))
+	}
+
+	return properties.Name
+}
+
+// MarshalText makes tile kinds appear by name in JSON
+func (k TileKind) MarshalText() ([]byte, error) {
+	if _, ok := tileProperties[k]; !ok {
+		return nil, fmt.Errorf("not a valid tile kind: %d", int(k))
+	}
+
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText parses tile kind names
+func (k *TileKind) UnmarshalText(text []byte) error {
+	kind, err := ParseTileKind(string(text))
+	if err != nil {
+		return err
+	}
+
+	*k = kind
+
+	return nil
+}
+
+// ParseTileKind returns the TileKind with the given name
+func ParseTileKind(name string) (TileKind, error) {
+	for kind, properties := range tileProperties {
+		if strings.EqualFold(properties.Name, name) {
+			return kind, nil
+		}
+	}
+
+	return Floor, fmt.Errorf("not a valid tile kind: %s", name)
+}
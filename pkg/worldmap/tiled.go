@@ -0,0 +1,186 @@
+package worldmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// tiledMap is the subset of the Tiled JSON map format that we use. See https://doc.mapeditor.org/en/stable/reference/json-map-format/
+type tiledMap struct {
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	Infinite bool           `json:"infinite"`
+	Layers   []tiledLayer   `json:"layers"`
+	Tilesets []tiledTileset `json:"tilesets"`
+}
+
+type tiledLayer struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Encoding string `json:"encoding"`
+	Data     []int  `json:"data"`
+}
+
+type tiledTileset struct {
+	FirstGID int         `json:"firstgid"`
+	Source   string      `json:"source"`
+	Tiles    []tiledTile `json:"tiles"`
+}
+
+type tiledTile struct {
+	ID         int             `json:"id"`
+	Type       string          `json:"type"`
+	Class      string          `json:"class"`
+	Properties []tiledProperty `json:"properties"`
+}
+
+type tiledProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// tiledFlipFlags are the bits of a global tile ID that tell if the tile is flipped, which doesn't matter to us
+const tiledFlipFlags = 0xF0000000
+
+const tiledKindProperty = "kind"
+
+// ParseTiled parses a map exported by the Tiled map editor in JSON format. Tilesets must be embedded in the map, and
+// layer data must be uncompressed CSV (the JSON default).
+//
+// The kind of each tile is decided by the tile's custom property "kind", or otherwise its class (called type in older
+// versions of Tiled), for instance "wall". Tiles without a kind are floor. Positions with no tile in any layer are walls,
+// as there is nothing to walk on. Tile layers are stacked in order, so tiles in later layers override earlier ones.
+func ParseTiled(r io.Reader) (*WorldMap, error) {
+	var m tiledMap
+
+	err := json.NewDecoder(r).Decode(&m)
+	if err != nil {
+		return nil, fmt.Errorf("decoding Tiled map: %w", err)
+	}
+
+	if m.Infinite {
+		return nil, fmt.Errorf("infinite Tiled maps are not supported")
+	}
+
+	if m.Width <= 0 || m.Height <= 0 {
+		return nil, fmt.Errorf("width and height must be positive, was %dx%d", m.Width, m.Height)
+	}
+
+	kinds, err := tiledKindsByGID(m.Tilesets)
+	if err != nil {
+		return nil, err
+	}
+
+	tiles := make([][]TileKind, m.Height)
+	for y := range tiles {
+		tiles[y] = make([]TileKind, m.Width)
+		for x := range tiles[y] {
+			tiles[y][x] = Wall
+		}
+	}
+
+	for _, layer := range m.Layers {
+		if layer.Type != "tilelayer" {
+			continue
+		}
+
+		if layer.Encoding != "" && layer.Encoding != "csv" {
+			return nil, fmt.Errorf("layer %s: unsupported encoding %s", layer.Name, layer.Encoding)
+		}
+
+		if len(layer.Data) != m.Width*m.Height {
+			return nil, fmt.Errorf("layer %s: expected %d tiles, got %d", layer.Name, m.Width*m.Height, len(layer.Data))
+		}
+
+		for i, gid := range layer.Data {
+			gid &^= tiledFlipFlags
+			if gid == 0 {
+				continue
+			}
+
+			kind, ok := kinds(gid)
+			if !ok {
+				return nil, fmt.Errorf("layer %s: tile %d is not in any tileset", layer.Name, gid)
+			}
+
+			tiles[i/m.Width][i%m.Width] = kind
+		}
+	}
+
+	return newFromTiles(tiles)
+}
+
+// tiledKindsByGID returns a function that looks up the tile kind of a global tile ID
+func tiledKindsByGID(tilesets []tiledTileset) (func(gid int) (TileKind, bool), error) {
+	sorted := make([]tiledTileset, len(tilesets))
+	copy(sorted, tilesets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].FirstGID > sorted[j].FirstGID })
+
+	kindsByTileset := make([]map[int]TileKind, len(sorted))
+
+	for i, tileset := range sorted {
+		if tileset.Source != "" {
+			return nil, fmt.Errorf("external tileset %s is not supported, embed it in the map", tileset.Source)
+		}
+
+		kindsByTileset[i] = make(map[int]TileKind)
+
+		for _, tile := range tileset.Tiles {
+			kind, ok, err := tile.kind()
+			if err != nil {
+				return nil, fmt.Errorf("tile %d in tileset %d: %w", tile.ID, tileset.FirstGID, err)
+			}
+
+			if ok {
+				kindsByTileset[i][tile.ID] = kind
+			}
+		}
+	}
+
+	return func(gid int) (TileKind, bool) {
+		for i, tileset := range sorted {
+			if gid >= tileset.FirstGID {
+				kind, ok := kindsByTileset[i][gid-tileset.FirstGID]
+				if !ok {
+					return Floor, true
+				}
+
+				return kind, true
+			}
+		}
+
+		return Floor, false
+	}, nil
+}
+
+// kind returns the tile's kind, and false if the tile doesn't specify one
+func (t tiledTile) kind() (TileKind, bool, error) {
+	name := t.Class
+	if name == "" {
+		name = t.Type
+	}
+
+	for _, p := range t.Properties {
+		if p.Name == tiledKindProperty {
+			value, ok := p.Value.(string)
+			if !ok {
+				return Floor, false, fmt.Errorf("property %s must be a string", tiledKindProperty)
+			}
+
+			name = value
+		}
+	}
+
+	if name == "" {
+		return Floor, false, nil
+	}
+
+	kind, err := ParseTileKind(name)
+	if err != nil {
+		return Floor, false, err
+	}
+
+	return kind, true, nil
+}
@@ -17,14 +17,13 @@ var Axis = struct { //nolint:gochecknoglobals
 	Y: 2, //nolint:gomnd
 }
 
-// WorldMap is a world map
+// WorldMap is a world map. Tiles contains the kind of each tile, indexed by [y][x] relative to MinY and MinX.
 type WorldMap struct {
-	Type  string  `json:"type,omitempty"`
-	MinX  int     `json:"minX,omitempty"`
-	MaxX  int     `json:"maxX,omitempty"`
-	MinY  int     `json:"minY,omitempty"`
-	MaxY  int     `json:"maxY,omitempty"`
-	Tiles [][]int `json:"tiles,omitempty"`
+	MinX  int          `json:"minX,omitempty"`
+	MaxX  int          `json:"maxX,omitempty"`
+	MinY  int          `json:"minY,omitempty"`
+	MaxY  int          `json:"maxY,omitempty"`
+	Tiles [][]TileKind `json:"tiles,omitempty"`
 }
 
 // IsInMap returns whether a point on the axis of a given type, is within the map
@@ -47,12 +46,93 @@ func (m *WorldMap) yIsInMap(y int) bool {
 	return y >= m.MinY && y <= m.MaxY
 }
 
+// TileAt returns the kind of the tile at the given position. It returns false if there is no tile there.
+func (m *WorldMap) TileAt(x int, y int) (TileKind, bool) {
+	row := y - m.MinY
+	column := x - m.MinX
+
+	if row < 0 || row >= len(m.Tiles) || column < 0 || column >= len(m.Tiles[row]) {
+		return Wall, false
+	}
+
+	return m.Tiles[row][column], true
+}
+
+// SetTile sets the kind of the tile at the given position
+func (m *WorldMap) SetTile(x int, y int, kind TileKind) error {
+	if _, ok := m.TileAt(x, y); !ok {
+		return fmt.Errorf("no tile at (%d, %d)", x, y)
+	}
+
+	m.Tiles[y-m.MinY][x-m.MinX] = kind
+
+	return nil
+}
+
+// IsWalkable returns whether the tile at the given position can be walked on. Positions without a tile can't.
+func (m *WorldMap) IsWalkable(x int, y int) bool {
+	kind, ok := m.TileAt(x, y)
+
+	return ok && kind.IsWalkable()
+}
+
+// NearestWalkable returns the walkable position closest to (x, y), measured in steps between neighbouring tiles. It
+// returns false if no tile in the map is walkable.
+func (m *WorldMap) NearestWalkable(x int, y int) (int, int, bool) {
+	type position struct{ x, y int }
+
+	start := position{x, y}
+	if _, ok := m.TileAt(x, y); !ok {
+		start = position{clamp(x, m.MinX, m.MinX+m.width()-1), clamp(y, m.MinY, m.MinY+len(m.Tiles)-1)}
+	}
+
+	visited := map[position]bool{start: true}
+	queue := []position{start}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		if m.IsWalkable(p.x, p.y) {
+			return p.x, p.y, true
+		}
+
+		for _, n := range []position{{p.x + 1, p.y}, {p.x - 1, p.y}, {p.x, p.y + 1}, {p.x, p.y - 1}} {
+			if _, ok := m.TileAt(n.x, n.y); ok && !visited[n] {
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+func (m *WorldMap) width() int {
+	if len(m.Tiles) == 0 {
+		return 0
+	}
+
+	return len(m.Tiles[0])
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
+
 // New returns a new WorldMap
 func New(maxX int, maxY int) *WorldMap {
 	tiles := generateTiles(maxX, maxY)
 
 	return &WorldMap{
-		Type:  "mapCreate",
 		MinX:  0,
 		MaxX:  maxX,
 		MinY:  0,
@@ -61,15 +141,15 @@ func New(maxX int, maxY int) *WorldMap {
 	}
 }
 
-func generateTiles(maxX, maxY int) [][]int {
-	ys := make([][]int, maxY)
+func generateTiles(maxX, maxY int) [][]TileKind {
+	ys := make([][]TileKind, maxY)
 
 	for y := 0; y < maxY; y++ {
-		xs := make([]int, maxX)
+		xs := make([]TileKind, maxX)
 		ys[y] = xs
 
 		for x := 0; x < maxX; x++ {
-			xs[x] = y*maxX + x
+			xs[x] = Floor
 		}
 	}
 
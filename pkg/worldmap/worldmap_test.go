@@ -1,12 +1,14 @@
 package worldmap_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/yngvark/gr-zombie/pkg/worldmap"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 //nolint:funlen
@@ -72,3 +74,61 @@ func TestWorldMap(t *testing.T) {
 		})
 	}
 }
+
+func TestTiles(t *testing.T) {
+	t.Run("Should have walkability and movement cost per tile kind", func(t *testing.T) {
+		assert.True(t, worldmap.Floor.IsWalkable())
+		assert.True(t, worldmap.Door.IsWalkable())
+		assert.True(t, worldmap.Water.IsWalkable())
+		assert.False(t, worldmap.Wall.IsWalkable())
+
+		assert.Equal(t, 1, worldmap.Floor.MovementCost())
+		assert.Greater(t, worldmap.Water.MovementCost(), worldmap.Floor.MovementCost())
+	})
+
+	t.Run("Should only be walkable on walkable tiles inside the map", func(t *testing.T) {
+		m := worldmap.New(3, 2)
+		require.NoError(t, m.SetTile(1, 0, worldmap.Wall))
+
+		assert.True(t, m.IsWalkable(0, 0))
+		assert.False(t, m.IsWalkable(1, 0))
+		assert.False(t, m.IsWalkable(3, 0))
+		assert.False(t, m.IsWalkable(0, -1))
+		assert.Error(t, m.SetTile(3, 0, worldmap.Wall))
+	})
+
+	t.Run("Should find nearest walkable tile", func(t *testing.T) {
+		m := worldmap.New(3, 3)
+		for _, p := range [][2]int{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}} {
+			require.NoError(t, m.SetTile(p[0], p[1], worldmap.Wall))
+		}
+
+		x, y, ok := m.NearestWalkable(1, 1)
+
+		assert.True(t, ok)
+		assert.Equal(t, [2]int{2, 1}, [2]int{x, y})
+	})
+
+	t.Run("Should marshal tile kinds by name", func(t *testing.T) {
+		// Given
+		m := worldmap.New(2, 1)
+		require.NoError(t, m.SetTile(1, 0, worldmap.Water))
+
+		// When
+		mapJSON, err := json.Marshal(m)
+		require.NoError(t, err)
+
+		// Then
+		assert.JSONEq(t, `{"maxX":2,"maxY":1,"tiles":[["floor","water"]]}`, string(mapJSON))
+
+		var unmarshalled worldmap.WorldMap
+
+		require.NoError(t, json.Unmarshal(mapJSON, &unmarshalled))
+		assert.Equal(t, m, &unmarshalled)
+	})
+
+	t.Run("Should fail on unknown tile kind names", func(t *testing.T) {
+		_, err := worldmap.ParseTileKind("lava")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,166 @@
+package zombie
+
+import (
+	"fmt"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+)
+
+// Behaviour decides where a Zombie moves
+type Behaviour interface {
+	// Next returns the position the zombie moves to from where it is now. Returning the zombie's current position
+	// makes it stay put.
+	Next(z *Zombie) (worldmap.Point, error)
+}
+
+// Targets returns the positions of whatever zombies are after, typically players. A nil Targets means there are none.
+type Targets func() []worldmap.Point
+
+func (t Targets) positions() []worldmap.Point {
+	if t == nil {
+		return nil
+	}
+
+	return t()
+}
+
+// Disturbance returns whether a zombie has been disturbed
+type Disturbance func(z *Zombie) bool
+
+// RandomWalk makes a zombie stumble around randomly, one step on each axis at a time
+type RandomWalk struct{}
+
+// Next returns a random neighbouring position, or the current position
+func (RandomWalk) Next(z *Zombie) (worldmap.Point, error) {
+	newX, err := z.getNewCoordPart(z.X, z.Y, worldmap.Axis.X)
+	if err != nil {
+		return worldmap.Point{}, fmt.Errorf("could not get new x coordinate: %w", err)
+	}
+
+	newY, err := z.getNewCoordPart(newX, z.Y, worldmap.Axis.Y)
+	if err != nil {
+		return worldmap.Point{}, fmt.Errorf("could not get new y coordinate: %w", err)
+	}
+
+	return worldmap.Point{X: newX, Y: newY}, nil
+}
+
+// Patrol makes a zombie walk between waypoints, in order, starting over after the last one. Waypoints that can't be
+// reached are skipped.
+type Patrol struct {
+	pathfinder *worldmap.Pathfinder
+	waypoints  []worldmap.Point
+	current    int
+}
+
+// Next returns the next position on the way to the current waypoint
+func (p *Patrol) Next(z *Zombie) (worldmap.Point, error) {
+	position := z.Position()
+
+	for range p.waypoints {
+		waypoint := p.waypoints[p.current]
+
+		if waypoint != position {
+			if path, ok := p.pathfinder.FindPath(position, waypoint); ok {
+				return path[1], nil
+			}
+		}
+
+		p.current = (p.current + 1) % len(p.waypoints)
+	}
+
+	return position, nil
+}
+
+// NewPatrol returns a new Patrol. Each zombie needs its own Patrol, since it keeps track of where the zombie is going.
+func NewPatrol(pathfinder *worldmap.Pathfinder, waypoints ...worldmap.Point) *Patrol {
+	return &Patrol{
+		pathfinder: pathfinder,
+		waypoints:  waypoints,
+	}
+}
+
+// Chase makes a zombie walk towards the nearest target. The zombie stays put if there are no targets it can reach.
+type Chase struct {
+	pathfinder *worldmap.Pathfinder
+	targets    Targets
+}
+
+// Next returns the next position on the shortest path to the nearest target
+func (c *Chase) Next(z *Zombie) (worldmap.Point, error) {
+	position := z.Position()
+
+	var shortest []worldmap.Point
+
+	for _, target := range c.targets.positions() {
+		path, ok := c.pathfinder.FindPath(position, target)
+		if ok && (shortest == nil || len(path) < len(shortest)) {
+			shortest = path
+		}
+	}
+
+	if len(shortest) < 2 { //nolint:gomnd
+		// No reachable targets, or the zombie is already there
+		return position, nil
+	}
+
+	return shortest[1], nil
+}
+
+// NewChase returns a new Chase
+func NewChase(pathfinder *worldmap.Pathfinder, targets Targets) *Chase {
+	return &Chase{
+		pathfinder: pathfinder,
+		targets:    targets,
+	}
+}
+
+// Idle makes a zombie stay put until it is disturbed, and then behave some other way from then on
+type Idle struct {
+	disturbed Disturbance
+	then      Behaviour
+	awake     bool
+}
+
+// Next returns the current position until the zombie is disturbed, and then the position chosen by the other behaviour
+func (i *Idle) Next(z *Zombie) (worldmap.Point, error) {
+	if !i.awake && i.disturbed(z) {
+		i.awake = true
+	}
+
+	if !i.awake {
+		return z.Position(), nil
+	}
+
+	return i.then.Next(z)
+}
+
+// NewIdle returns a new Idle. Each zombie needs its own Idle, since it remembers whether the zombie has been disturbed.
+func NewIdle(disturbed Disturbance, then Behaviour) *Idle {
+	return &Idle{
+		disturbed: disturbed,
+		then:      then,
+	}
+}
+
+// TargetWithin returns a Disturbance that disturbs zombies when a target comes within radius steps, diagonal steps
+// included
+func TargetWithin(targets Targets, radius int) Disturbance {
+	return func(z *Zombie) bool {
+		for _, target := range targets.positions() {
+			if abs(target.X-z.X) <= radius && abs(target.Y-z.Y) <= radius {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
@@ -0,0 +1,149 @@
+package zombie_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomWalk(t *testing.T) {
+	t.Run("Should walk the same way for the same seed", func(t *testing.T) {
+		// Given
+		m := worldmap.New(20, 10)                                                                            //nolint:gomnd
+		z := zombie.NewZombieWithBehaviour("1", 10, 5, m, rand.New(rand.NewSource(45)), zombie.RandomWalk{}) //nolint:gosec,gomnd
+
+		// When+Then
+		assertPositions(t, z, worldmap.Point{X: 9, Y: 5}, worldmap.Point{X: 8, Y: 4}, worldmap.Point{X: 9, Y: 4})
+	})
+}
+
+func TestPatrol(t *testing.T) {
+	t.Run("Should walk between waypoints and start over", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.....
+.###.
+.....`)
+		patrol := zombie.NewPatrol(worldmap.NewPathfinder(m, worldmap.FourWay),
+			worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 2, Y: 0})
+		z := zombie.NewZombieWithBehaviour("1", 0, 2, m, rand.New(rand.NewSource(45)), patrol) //nolint:gosec,gomnd
+
+		// When+Then
+		assertPositions(t, z,
+			worldmap.Point{X: 0, Y: 1}, worldmap.Point{X: 0, Y: 0},
+			worldmap.Point{X: 1, Y: 0}, worldmap.Point{X: 2, Y: 0},
+			worldmap.Point{X: 1, Y: 0}, worldmap.Point{X: 0, Y: 0},
+			worldmap.Point{X: 1, Y: 0})
+	})
+
+	t.Run("Should skip unreachable waypoints", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+..#.`)
+		patrol := zombie.NewPatrol(worldmap.NewPathfinder(m, worldmap.FourWay),
+			worldmap.Point{X: 3, Y: 0}, worldmap.Point{X: 1, Y: 0})
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), patrol) //nolint:gosec,gomnd
+
+		// When+Then
+		assertPositions(t, z, worldmap.Point{X: 1, Y: 0}, worldmap.Point{X: 1, Y: 0})
+	})
+}
+
+func TestChase(t *testing.T) {
+	t.Run("Should walk towards the nearest target", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.......
+.......`)
+		targets := func() []worldmap.Point {
+			return []worldmap.Point{{X: 6, Y: 1}, {X: 0, Y: 1}}
+		}
+		chase := zombie.NewChase(worldmap.NewPathfinder(m, worldmap.EightWay), targets)
+		z := zombie.NewZombieWithBehaviour("1", 2, 0, m, rand.New(rand.NewSource(45)), chase) //nolint:gosec,gomnd
+
+		// When+Then
+		assertPositions(t, z, worldmap.Point{X: 1, Y: 1}, worldmap.Point{X: 0, Y: 1}, worldmap.Point{X: 0, Y: 1})
+	})
+
+	t.Run("Should follow moving targets", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.......`)
+		target := worldmap.Point{X: 3, Y: 0}
+		chase := zombie.NewChase(worldmap.NewPathfinder(m, worldmap.FourWay), func() []worldmap.Point {
+			return []worldmap.Point{target}
+		})
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), chase) //nolint:gosec,gomnd
+
+		// When
+		z = assertPositions(t, z, worldmap.Point{X: 1, Y: 0})
+		target = worldmap.Point{X: 0, Y: 0}
+
+		// Then
+		assertPositions(t, z, worldmap.Point{X: 0, Y: 0})
+	})
+
+	t.Run("Should stay put without reachable targets", func(t *testing.T) {
+		m := parseMap(t, `
+.#.`)
+		chase := zombie.NewChase(worldmap.NewPathfinder(m, worldmap.EightWay), func() []worldmap.Point {
+			return []worldmap.Point{{X: 2, Y: 0}}
+		})
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), chase) //nolint:gosec,gomnd
+
+		assertPositions(t, z, worldmap.Point{X: 0, Y: 0})
+	})
+}
+
+func TestIdle(t *testing.T) {
+	t.Run("Should stay put until disturbed, and then behave differently", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+..........`)
+		target := worldmap.Point{X: 9, Y: 0}
+		targets := func() []worldmap.Point { return []worldmap.Point{target} }
+		idle := zombie.NewIdle(
+			zombie.TargetWithin(targets, 3),
+			zombie.NewChase(worldmap.NewPathfinder(m, worldmap.FourWay), targets))
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), idle) //nolint:gosec,gomnd
+
+		// When
+		z = assertPositions(t, z, worldmap.Point{X: 0, Y: 0}, worldmap.Point{X: 0, Y: 0})
+		target = worldmap.Point{X: 3, Y: 0}
+		z = assertPositions(t, z, worldmap.Point{X: 1, Y: 0})
+		target = worldmap.Point{X: 9, Y: 0}
+
+		// Then
+		assertPositions(t, z, worldmap.Point{X: 2, Y: 0}, worldmap.Point{X: 3, Y: 0})
+	})
+}
+
+// assertPositions moves the zombie once for each expected position, and returns the zombie after the last move
+func assertPositions(t *testing.T, z *zombie.Zombie, expected ...worldmap.Point) *zombie.Zombie {
+	for _, position := range expected {
+		var (
+			move *zombie.Move
+			err  error
+		)
+
+		z, move, err = z.Move()
+		require.NoError(t, err)
+
+		assert.Equal(t, zombie.NewZombieMove(z.ID, position.X, position.Y), move)
+	}
+
+	return z
+}
+
+func parseMap(t *testing.T, ascii string) *worldmap.WorldMap {
+	m, err := worldmap.ParseASCII(strings.NewReader(strings.TrimPrefix(ascii, "\n")))
+	require.NoError(t, err)
+
+	return m
+}
@@ -0,0 +1,143 @@
+package zombie
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+)
+
+const (
+	// idleToWanderingChance is the chance, 1 in n per tick, that an idle zombie starts wandering
+	idleToWanderingChance = 5
+	// wanderingToIdleChance is the chance, 1 in n per tick, that a wandering zombie stops
+	wanderingToIdleChance = 20
+	// attackRange is how close, in steps, a zombie must be to a target to attack it
+	attackRange = 1
+)
+
+// Stateful is implemented by behaviours that keep track of what the zombie is up to
+type Stateful interface {
+	State() State
+}
+
+// Killable is implemented by behaviours that keep track of whether the zombie is dead, see Zombie.Kill
+type Killable interface {
+	Kill()
+}
+
+// Dead makes a zombie do nothing, ever again. Zombie.Kill gives it to zombies whose behaviour isn't Killable.
+type Dead struct{}
+
+// Next returns the zombie's current position
+func (Dead) Next(z *Zombie) (worldmap.Point, error) {
+	return z.Position(), nil
+}
+
+// State returns StateDead
+func (Dead) State() State {
+	return StateDead
+}
+
+// StateMachine makes a zombie behave according to what it perceives, as a finite state machine:
+//
+//   - Idle and wandering zombies switch between standing still and walking randomly now and then.
+//   - A zombie that hears a noise becomes alerted, and walks towards where the noise came from. If it gets there without
+//     perceiving anything else, it starts wandering.
+//   - A zombie that sees a target chases the nearest one it sees. If it loses sight of its targets, it becomes alerted,
+//     and walks towards where it last saw one.
+//   - A zombie next to a target attacks it.
+//   - A dead zombie does nothing, ever again. Zombies die when players attack them, see Zombie.Kill.
+type StateMachine struct {
+	pathfinder *worldmap.Pathfinder
+	senses     Senses
+	targets    Targets
+	noises     Noises
+	state      State
+	// goal is where an alerted or chasing zombie is heading
+	goal worldmap.Point
+}
+
+// State returns the zombie's current State
+func (s *StateMachine) State() State {
+	return s.state
+}
+
+// Kill makes the zombie dead. It implements Killable.
+func (s *StateMachine) Kill() {
+	s.state = StateDead
+}
+
+// Next updates the zombie's State based on what it perceives, and returns where it moves
+func (s *StateMachine) Next(z *Zombie) (worldmap.Point, error) {
+	position := z.Position()
+
+	if s.state == StateDead {
+		return position, nil
+	}
+
+	perception := s.senses.Perceive(z.WorldMap, position, s.targets.positions(), s.noises.positions())
+
+	if seen, ok := nearest(position, perception.Seen); ok {
+		s.goal = seen
+
+		if abs(seen.X-position.X) <= attackRange && abs(seen.Y-position.Y) <= attackRange {
+			s.state = StateAttacking
+
+			return position, nil
+		}
+
+		s.state = StateChasing
+
+		return s.stepTowardsGoal(position), nil
+	}
+
+	if heard, ok := nearest(position, perception.Heard); ok {
+		s.state = StateAlerted
+		s.goal = heard
+	} else if s.state == StateChasing || s.state == StateAttacking {
+		// Lost sight of the target, so go look where it was last seen
+		s.state = StateAlerted
+	}
+
+	switch s.state {
+	case StateAlerted:
+		return s.stepTowardsGoal(position), nil
+	case StateIdle:
+		if z.Rand.Intn(idleToWanderingChance) == 0 { //nolint:gosec
+			s.state = StateWandering
+		}
+
+		return position, nil
+	default: // StateWandering
+		if z.Rand.Intn(wanderingToIdleChance) == 0 { //nolint:gosec
+			s.state = StateIdle
+
+			return position, nil
+		}
+
+		return RandomWalk{}.Next(z)
+	}
+}
+
+// stepTowardsGoal returns the next position on the way to the goal. The zombie starts wandering if it is at the goal,
+// or can't get there.
+func (s *StateMachine) stepTowardsGoal(position worldmap.Point) worldmap.Point {
+	path, ok := s.pathfinder.FindPath(position, s.goal)
+	if !ok || len(path) < 2 { //nolint:gomnd
+		s.state = StateWandering
+
+		return position
+	}
+
+	return path[1]
+}
+
+// NewStateMachine returns a new StateMachine, for a zombie that starts out idle. Each zombie needs its own
+// StateMachine.
+func NewStateMachine(pathfinder *worldmap.Pathfinder, senses Senses, targets Targets, noises Noises) *StateMachine {
+	return &StateMachine{
+		pathfinder: pathfinder,
+		senses:     senses,
+		targets:    targets,
+		noises:     noises,
+		state:      StateIdle,
+	}
+}
@@ -0,0 +1,194 @@
+package zombie_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yngvark/gr-zombie/pkg/worldmap"
+	"github.com/yngvark/gr-zombie/pkg/zombie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var testSenses = zombie.Senses{SightRadius: 5, HearingRadius: 8}
+
+func TestPerceive(t *testing.T) {
+	t.Run("Should see targets in sight, and hear noises through walls", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+..........
+.....#....
+..........`)
+		targets := []worldmap.Point{{X: 3, Y: 0}, {X: 6, Y: 1}, {X: 9, Y: 1}}
+		noises := []worldmap.Point{{X: 6, Y: 1}, {X: 9, Y: 0}}
+
+		// When
+		perception := testSenses.Perceive(m, worldmap.Point{X: 2, Y: 1}, targets, noises)
+
+		// Then
+		assert.Equal(t, []worldmap.Point{{X: 3, Y: 0}}, perception.Seen)
+		assert.Equal(t, []worldmap.Point{{X: 6, Y: 1}, {X: 9, Y: 0}}, perception.Heard)
+	})
+}
+
+//nolint:funlen
+func TestStateMachine(t *testing.T) {
+	t.Run("Should start idle, and switch between idling and wandering the same way for the same seed", func(t *testing.T) {
+		// Given
+		m := worldmap.New(20, 10) //nolint:gomnd
+
+		newZombie := func() *zombie.Zombie {
+			fsm := zombie.NewStateMachine(worldmap.NewPathfinder(m, worldmap.EightWay), testSenses, noPositions, noPositions)
+			return zombie.NewZombieWithBehaviour("1", 10, 5, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec,gomnd
+		}
+
+		z, sameSeedZombie := newZombie(), newZombie()
+
+		state, ok := z.State()
+		require.True(t, ok)
+		assert.Equal(t, zombie.StateIdle, state)
+
+		// When
+		states := make(map[zombie.State]bool)
+
+		for i := 0; i < 100; i++ {
+			var sameSeedState zombie.State
+
+			z, state = nextState(t, z)
+			sameSeedZombie, sameSeedState = nextState(t, sameSeedZombie)
+
+			// Then
+			require.Equal(t, sameSeedState, state)
+			require.Equal(t, sameSeedZombie.Position(), z.Position())
+
+			states[state] = true
+		}
+
+		assert.Equal(t, map[zombie.State]bool{zombie.StateIdle: true, zombie.StateWandering: true}, states)
+	})
+
+	t.Run("Should chase a target it sees, and attack it when next to it", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+........`)
+		targets := func() []worldmap.Point { return []worldmap.Point{{X: 4, Y: 0}} }
+		fsm := zombie.NewStateMachine(worldmap.NewPathfinder(m, worldmap.EightWay), testSenses, targets, noPositions)
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec,gomnd
+
+		// When
+		z = assertStates(t, z,
+			zombie.StateChasing, zombie.StateChasing, zombie.StateChasing, zombie.StateAttacking, zombie.StateAttacking)
+
+		// Then
+		assert.Equal(t, worldmap.Point{X: 3, Y: 0}, z.Position())
+	})
+
+	t.Run("Should not see targets behind walls", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+..#..`)
+		targets := func() []worldmap.Point { return []worldmap.Point{{X: 4, Y: 0}} }
+		fsm := zombie.NewStateMachine(worldmap.NewPathfinder(m, worldmap.EightWay), testSenses, targets, noPositions)
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec,gomnd
+
+		// When+Then
+		for i := 0; i < 20; i++ {
+			var state zombie.State
+
+			z, state = nextState(t, z)
+			assert.Contains(t, []zombie.State{zombie.StateIdle, zombie.StateWandering}, state)
+		}
+	})
+
+	t.Run("Should investigate noises, and start wandering when there's nothing there", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.......
+.#####.
+.......`)
+		var noises []worldmap.Point
+		fsm := zombie.NewStateMachine(
+			worldmap.NewPathfinder(m, worldmap.FourWay), testSenses, noPositions, func() []worldmap.Point { return noises })
+		z := zombie.NewZombieWithBehaviour("1", 3, 0, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec,gomnd
+
+		// When
+		noises = []worldmap.Point{{X: 4, Y: 2}}
+		z = assertStates(t, z, zombie.StateAlerted)
+		noises = nil
+
+		// Then
+		z = assertStates(t, z, zombie.StateAlerted, zombie.StateAlerted, zombie.StateAlerted, zombie.StateAlerted)
+		assert.Equal(t, worldmap.Point{X: 6, Y: 2}, z.Position())
+
+		z = assertStates(t, z, zombie.StateAlerted, zombie.StateAlerted)
+		assert.Equal(t, worldmap.Point{X: 4, Y: 2}, z.Position())
+
+		assertStates(t, z, zombie.StateWandering)
+	})
+
+	t.Run("Should go where it last saw a target when losing sight of it", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+........
+.....#..
+........`)
+		target := worldmap.Point{X: 4, Y: 1}
+		targets := func() []worldmap.Point { return []worldmap.Point{target} }
+		fsm := zombie.NewStateMachine(worldmap.NewPathfinder(m, worldmap.FourWay), testSenses, targets, noPositions)
+		z := zombie.NewZombieWithBehaviour("1", 0, 1, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec,gomnd
+
+		// When
+		z = assertStates(t, z, zombie.StateChasing)
+		target = worldmap.Point{X: 7, Y: 1}
+
+		// Then
+		z = assertStates(t, z, zombie.StateAlerted, zombie.StateAlerted, zombie.StateAlerted)
+		assert.Equal(t, worldmap.Point{X: 4, Y: 1}, z.Position())
+
+		assertStates(t, z, zombie.StateWandering)
+	})
+
+	t.Run("Should do nothing when dead", func(t *testing.T) {
+		// Given
+		m := parseMap(t, `
+.....`)
+		targets := func() []worldmap.Point { return []worldmap.Point{{X: 4, Y: 0}} }
+		fsm := zombie.NewStateMachine(worldmap.NewPathfinder(m, worldmap.FourWay), testSenses, targets, noPositions)
+		z := zombie.NewZombieWithBehaviour("1", 0, 0, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec,gomnd
+
+		// When
+		fsm.Kill()
+
+		// Then
+		z = assertStates(t, z, zombie.StateDead, zombie.StateDead)
+		assert.Equal(t, worldmap.Point{X: 0, Y: 0}, z.Position())
+	})
+}
+
+func noPositions() []worldmap.Point {
+	return nil
+}
+
+func nextState(t *testing.T, z *zombie.Zombie) (*zombie.Zombie, zombie.State) {
+	z, _, err := z.Move()
+	require.NoError(t, err)
+
+	state, ok := z.State()
+	require.True(t, ok)
+
+	return z, state
+}
+
+// assertStates moves the zombie once for each expected state, and returns the zombie after the last move
+func assertStates(t *testing.T, z *zombie.Zombie, expected ...zombie.State) *zombie.Zombie {
+	for _, expectedState := range expected {
+		var state zombie.State
+
+		z, state = nextState(t, z)
+		assert.Equal(t, expectedState, state)
+	}
+
+	return z
+}
@@ -0,0 +1,74 @@
+package zombie
+
+import "github.com/yngvark/gr-zombie/pkg/worldmap"
+
+// Noises returns the positions where noises were made since the last tick, typically by players doing something. A nil
+// Noises means there are none.
+type Noises func() []worldmap.Point
+
+func (n Noises) positions() []worldmap.Point {
+	if n == nil {
+		return nil
+	}
+
+	return n()
+}
+
+// Senses decides how far a zombie can see and hear. Distances are measured in tiles, as the crow flies.
+type Senses struct {
+	// SightRadius is how far away a zombie can see targets. Tiles that block sight, like walls, block the view.
+	SightRadius int
+	// HearingRadius is how far away a zombie can hear noises. Walls don't stop sound.
+	HearingRadius int
+}
+
+// Perception is what a zombie perceives of its surroundings
+type Perception struct {
+	// Seen are the positions of the targets the zombie sees
+	Seen []worldmap.Point
+	// Heard are the positions of the noises the zombie hears
+	Heard []worldmap.Point
+}
+
+// Perceive returns what a zombie at the given position perceives of targets and noises
+func (s Senses) Perceive(m *worldmap.WorldMap, position worldmap.Point, targets []worldmap.Point,
+	noises []worldmap.Point) Perception {
+	var perception Perception
+
+	for _, target := range targets {
+		if isWithin(position, target, s.SightRadius) && m.HasLineOfSight(position, target) {
+			perception.Seen = append(perception.Seen, target)
+		}
+	}
+
+	for _, noise := range noises {
+		if isWithin(position, noise, s.HearingRadius) {
+			perception.Heard = append(perception.Heard, noise)
+		}
+	}
+
+	return perception
+}
+
+func isWithin(from worldmap.Point, to worldmap.Point, radius int) bool {
+	dx, dy := to.X-from.X, to.Y-from.Y
+
+	return dx*dx+dy*dy <= radius*radius
+}
+
+// nearest returns the position closest to from, as the crow flies. It returns false if there are no positions.
+func nearest(from worldmap.Point, positions []worldmap.Point) (worldmap.Point, bool) {
+	var (
+		closest     worldmap.Point
+		closestDist = -1
+	)
+
+	for _, p := range positions {
+		dx, dy := p.X-from.X, p.Y-from.Y
+		if dist := dx*dx + dy*dy; closestDist < 0 || dist < closestDist {
+			closest, closestDist = p, dist
+		}
+	}
+
+	return closest, closestDist >= 0
+}
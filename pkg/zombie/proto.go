@@ -0,0 +1,57 @@
+package zombie
+
+import (
+	"github.com/yngvark/gr-zombie/pkg/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf field numbers, see protocol.proto
+const (
+	protoIDField     protowire.Number = 1
+	protoStateField  protowire.Number = 2
+	protoStatesField protowire.Number = 1
+)
+
+// AppendProto implements protocol.ProtoPayload
+func (c *StateChange) AppendProto(b []byte) []byte {
+	b = protocol.AppendProtoString(b, protoIDField, c.ID)
+
+	return protocol.AppendProtoString(b, protoStateField, string(c.State))
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (c *StateChange) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		switch f.Number {
+		case protoIDField:
+			c.ID = f.String()
+		case protoStateField:
+			c.State = State(f.String())
+		}
+
+		return nil
+	})
+}
+
+// AppendProto implements protocol.ProtoPayload
+func (c *StateChanges) AppendProto(b []byte) []byte {
+	for _, state := range c.States {
+		b = protocol.AppendProtoMessage(b, protoStatesField, state.AppendProto)
+	}
+
+	return b
+}
+
+// UnmarshalProto implements protocol.ProtoPayload
+func (c *StateChanges) UnmarshalProto(b []byte) error {
+	return protocol.RangeProtoFields(b, func(f protocol.ProtoField) error {
+		if f.Number != protoStatesField {
+			return nil
+		}
+
+		state := &StateChange{}
+		c.States = append(c.States, state)
+
+		return state.UnmarshalProto(f.Bytes())
+	})
+}
@@ -10,33 +10,68 @@ import (
 
 // Zombie is a horrible monster
 type Zombie struct {
-	ID       string
-	X        int
-	Y        int
-	WorldMap *worldmap.WorldMap
-	Rand     *rand.Rand
+	ID        string
+	X         int
+	Y         int
+	WorldMap  *worldmap.WorldMap
+	Rand      *rand.Rand
+	Behaviour Behaviour
 }
 
-// Move moves the Zombie
-func (z *Zombie) Move() (*Zombie, *Move, error) {
-	newX, err := z.getNewCoordPart(z.X, worldmap.Axis.X)
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not get new x coordinate: %w", err)
+// Position returns where the Zombie is
+func (z *Zombie) Position() worldmap.Point {
+	return worldmap.Point{X: z.X, Y: z.Y}
+}
+
+// State returns what the Zombie is up to. It returns false if the Zombie's Behaviour doesn't keep track of that.
+func (z *Zombie) State() (State, bool) {
+	stateful, ok := z.Behaviour.(Stateful)
+	if !ok {
+		return "", false
+	}
+
+	return stateful.State(), true
+}
+
+// Kill makes the Zombie dead. Dead zombies stay where they are, in StateDead. It returns false if the Zombie was already
+// dead.
+func (z *Zombie) Kill() bool {
+	if state, _ := z.State(); state == StateDead {
+		return false
+	}
+
+	if killable, ok := z.Behaviour.(Killable); ok {
+		killable.Kill()
+	} else {
+		z.Behaviour = Dead{}
 	}
 
-	newY, err := z.getNewCoordPart(z.Y, worldmap.Axis.Y)
+	return true
+}
+
+// Move moves the Zombie according to its Behaviour
+func (z *Zombie) Move() (*Zombie, *Move, error) {
+	next, err := z.Behaviour.Next(z)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not get new x coordinate: %w", err)
+		return nil, nil, fmt.Errorf("could not get next position: %w", err)
 	}
 
-	newZ := NewZombie(z.ID, newX, newY, z.WorldMap, z.Rand)
-	move := NewZombieMove(z.ID, newX, newY)
+	newZ := NewZombieWithBehaviour(z.ID, next.X, next.Y, z.WorldMap, z.Rand, z.Behaviour)
+	move := NewZombieMove(z.ID, next.X, next.Y)
 
 	return newZ, move, nil
 }
 
-func (z *Zombie) getNewCoordPart(currentValue int, axisType worldmap.AxisType) (int, error) {
+// getNewCoordPart returns a new value for the given axis of the position (x, y). The value stays the same if the new
+// position would be outside the map, or on a tile that can't be walked on.
+func (z *Zombie) getNewCoordPart(x int, y int, axisType worldmap.AxisType) (int, error) {
 	direction := z.Rand.Intn(3) - 1 //nolint:gomnd,gosec    // [-1, 1]
+
+	currentValue, suggestedX, suggestedY := x, x+direction, y
+	if axisType == worldmap.Axis.Y {
+		currentValue, suggestedX, suggestedY = y, x, y+direction
+	}
+
 	suggestion := currentValue + direction
 
 	isInMap, err := z.WorldMap.IsInMap(suggestion, axisType)
@@ -44,20 +79,37 @@ func (z *Zombie) getNewCoordPart(currentValue int, axisType worldmap.AxisType) (
 		return -1, fmt.Errorf("could not detect if value is within map: %w", err)
 	}
 
-	if isInMap {
+	if isInMap && z.WorldMap.IsWalkable(suggestedX, suggestedY) {
 		return suggestion, nil
 	}
 
 	return currentValue, nil
 }
 
-// NewZombie returns a new Zombie
+// NewZombie returns a new Zombie that walks randomly
 func NewZombie(id string, x int, y int, worldMap *worldmap.WorldMap, rnd *rand.Rand) *Zombie {
+	return NewZombieWithBehaviour(id, x, y, worldMap, rnd, RandomWalk{})
+}
+
+// NewZombieWithBehaviour returns a new Zombie. If behaviour is nil, the zombie walks randomly.
+func NewZombieWithBehaviour(
+	id string,
+	x int,
+	y int,
+	worldMap *worldmap.WorldMap,
+	rnd *rand.Rand,
+	behaviour Behaviour,
+) *Zombie {
+	if behaviour == nil {
+		behaviour = RandomWalk{}
+	}
+
 	return &Zombie{
-		ID:       id,
-		X:        x,
-		Y:        y,
-		WorldMap: worldMap,
-		Rand:     rnd,
+		ID:        id,
+		X:         x,
+		Y:         y,
+		WorldMap:  worldMap,
+		Rand:      rnd,
+		Behaviour: behaviour,
 	}
 }
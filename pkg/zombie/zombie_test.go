@@ -10,6 +10,7 @@ import (
 	"github.com/yngvark/gr-zombie/pkg/zombie"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestZombie(t *testing.T) {
@@ -28,8 +29,92 @@ func TestZombie(t *testing.T) {
 	})
 }
 
+func TestZombieObstacles(t *testing.T) {
+	t.Run("Should not walk through walls", func(t *testing.T) {
+		// Given
+		m := worldmap.New(20, 10) //nolint:gomnd
+		for y := 0; y < 10; y++ {
+			require.NoError(t, m.SetTile(10, y, worldmap.Wall))
+		}
+
+		z := zombie.NewZombie("1", 9, 5, m, rand.New(rand.NewSource(45))) //nolint:gosec,gomnd
+
+		for i := 0; i < 1000; i++ {
+			// When
+			var err error
+
+			z, _, err = z.Move()
+			require.NoError(t, err)
+
+			// Then
+			require.Less(t, z.X, 10, "zombie walked through the wall")
+		}
+	})
+
+	t.Run("Should stay put when surrounded by walls", func(t *testing.T) {
+		// Given
+		m := worldmap.New(3, 3)
+		for _, p := range [][2]int{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {2, 1}, {0, 2}, {1, 2}, {2, 2}} {
+			require.NoError(t, m.SetTile(p[0], p[1], worldmap.Wall))
+		}
+
+		z := zombie.NewZombie("1", 1, 1, m, rand.New(rand.NewSource(45))) //nolint:gosec,gomnd
+
+		for i := 0; i < 100; i++ {
+			// When
+			_, move, err := z.Move()
+			require.NoError(t, err)
+
+			// Then
+			assert.Equal(t, zombie.NewZombieMove("1", 1, 1), move)
+		}
+	})
+}
+
+func TestKill(t *testing.T) {
+	t.Run("Should make a zombie dead where it is, whatever its behaviour", func(t *testing.T) {
+		// Given
+		m := worldmap.New(3, 3)
+		z := zombie.NewZombie("1", 1, 1, m, rand.New(rand.NewSource(45))) //nolint:gosec
+
+		// When
+		killed := z.Kill()
+
+		// Then
+		assert.True(t, killed)
+
+		for i := 0; i < 10; i++ {
+			var err error
+
+			z, _, err = z.Move()
+			require.NoError(t, err)
+
+			state, ok := z.State()
+			assert.True(t, ok)
+			assert.Equal(t, zombie.StateDead, state)
+			assert.Equal(t, worldmap.Point{X: 1, Y: 1}, z.Position())
+		}
+
+		assert.False(t, z.Kill(), "zombies can only die once")
+	})
+
+	t.Run("Should kill zombies with a state machine through the state machine", func(t *testing.T) {
+		// Given
+		m := worldmap.New(3, 3)
+		fsm := zombie.NewStateMachine(worldmap.NewPathfinder(m, worldmap.FourWay), zombie.Senses{}, nil, nil)
+		z := zombie.NewZombieWithBehaviour("1", 1, 1, m, rand.New(rand.NewSource(45)), fsm) //nolint:gosec
+
+		// When
+		assert.True(t, z.Kill())
+
+		// Then
+		assert.Same(t, fsm, z.Behaviour)
+		assert.Equal(t, zombie.StateDead, fsm.State())
+	})
+}
+
 func assertNextPosition(t *testing.T, generator *gamelogic.Generator, x int, y int) {
-	move, err := generator.Next()
+	_, err := generator.Next()
 	assert.Nil(t, err)
-	assert.Equal(t, zombie.NewZombieMove("1", x, y), move)
+	assert.Equal(t, worldmap.Point{X: x, Y: y}, generator.Zombies()[0].Position())
 }
@@ -0,0 +1,40 @@
+package zombie
+
+// State is what a zombie is up to
+type State string
+
+// Zombie states
+const (
+	StateIdle      State = "idle"
+	StateWandering State = "wandering"
+	StateAlerted   State = "alerted"
+	StateChasing   State = "chasing"
+	StateAttacking State = "attacking"
+	StateDead      State = "dead"
+)
+
+// StateChange tells that a zombie changed state
+type StateChange struct {
+	ID    string `json:"id"`
+	State State  `json:"state"`
+}
+
+// NewZombieStateChange returns a new StateChange
+func NewZombieStateChange(id string, state State) *StateChange {
+	return &StateChange{
+		ID:    id,
+		State: state,
+	}
+}
+
+// StateChanges is a batch of state changes happening at the same time
+type StateChanges struct {
+	States []*StateChange `json:"states"`
+}
+
+// NewZombieStateChanges returns a new StateChanges
+func NewZombieStateChanges(states []*StateChange) *StateChanges {
+	return &StateChanges{
+		States: states,
+	}
+}